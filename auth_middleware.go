@@ -0,0 +1,127 @@
+package httpbara
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+	"strings"
+)
+
+const principalKey = "principal"
+
+// ErrMissingBearerToken is returned by BearerToken when the Authorization header does not carry a bearer token.
+var ErrMissingBearerToken = errors.New("missing bearer token")
+
+// principalContextKey is the request context key of the authenticated principal.
+type principalContextKey struct{}
+
+// TokenVerifier verifies the bearer token of a request and returns the context the request continues with,
+// e.g. carrying the principal set via WithPrincipal. An error rejects the request with 401.
+type TokenVerifier func(ctx *gin.Context, token string) (context.Context, error)
+
+type authMiddlewareDescriber struct {
+	Middleware Middleware `middleware:"auth"`
+}
+
+type authMiddleware struct {
+	authMiddlewareDescriber
+
+	verify TokenVerifier
+}
+
+// NewAuthMiddleware creates a middleware named "auth" that takes the bearer token from the Authorization header
+// and passes it to verify. The context returned by verify replaces the request context, so values it carries,
+// like the principal set via WithPrincipal, are available to the handlers. Requests without a bearer token or
+// failing verification are answered with casual.ErrUnauthorized and a `WWW-Authenticate: Bearer` header.
+//
+// **Example:**
+// ```go
+//
+//	auth, _ := httpbara.NewAuthMiddleware(func(ctx *gin.Context, token string) (context.Context, error) {
+//	    user, err := sessions.Lookup(ctx, token)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//
+//	    return httpbara.WithPrincipal(ctx.Request.Context(), user), nil
+//	})
+//
+//	func (h *Handler) Me(ctx context.Context) (*User, error) {
+//	    user, _ := httpbara.Principal(ctx).(*User)
+//	    // ...
+//	}
+//
+// ```
+func NewAuthMiddleware(verify TokenVerifier) (*Handler, error) {
+	if verify == nil {
+		return nil, errors.New("token verifier is nil")
+	}
+
+	am := authMiddleware{
+		verify: verify,
+	}
+
+	return AsHandler(&am)
+}
+
+func (am *authMiddleware) Middleware(ctx *gin.Context) {
+	token, err := BearerToken(ctx.GetHeader("Authorization"))
+	if err != nil {
+		am.unauthorized(ctx)
+		return
+	}
+
+	verified, err := am.verify(ctx, token)
+	if err != nil {
+		am.unauthorized(ctx)
+		return
+	}
+
+	if verified != nil {
+		ctx.Request = ctx.Request.WithContext(verified)
+		if principal := verified.Value(principalContextKey{}); principal != nil {
+			ctx.Set(principalKey, principal)
+		}
+	}
+
+	ctx.Next()
+}
+
+// unauthorized rejects the request with casual.ErrUnauthorized.
+func (am *authMiddleware) unauthorized(ctx *gin.Context) {
+	ctx.Header("WWW-Authenticate", "Bearer")
+	casual.Fail(ctx, casual.ErrUnauthorized)
+}
+
+// BearerToken returns the token of an Authorization header value using the Bearer scheme, matched
+// case-insensitively, or ErrMissingBearerToken.
+func BearerToken(authorization string) (string, error) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", ErrMissingBearerToken
+	}
+
+	if token = strings.TrimSpace(token); token == "" {
+		return "", ErrMissingBearerToken
+	}
+
+	return token, nil
+}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal, to be returned by a TokenVerifier.
+func WithPrincipal(ctx context.Context, principal any) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// Principal returns the principal stored by the auth middleware from a request context or a *gin.Context,
+// or nil when there is none.
+func Principal(ctx context.Context) any {
+	if gctx, ok := ctx.(*gin.Context); ok {
+		principal, _ := gctx.Get(principalKey)
+
+		return principal
+	}
+
+	return ctx.Value(principalContextKey{})
+}
@@ -0,0 +1,306 @@
+package httpbara
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"testing"
+)
+
+// benchRequest and benchResponse are the types bound and returned by the casual routes of benchHandler.
+type benchRequest struct {
+	ID    string `uri:"id"`
+	Query string `form:"q"`
+}
+
+type benchResponse struct {
+	ID string `json:"id"`
+}
+
+// benchHandlerDescriber declares 50 routes, half of them casual, with the tags most applications use.
+type benchHandlerDescriber struct {
+	Bench Group `group:"/bench" middlewares:"benchAuth"`
+
+	BenchAuth Middleware `middleware:"benchAuth"`
+
+	R00 Route `route:"GET /r00/:id" group:"bench"`
+	R01 Route `route:"GET /r01/:id" group:"bench" timeout:"5s"`
+	R02 Route `route:"GET /r02/:id" group:"bench" meta:"scope=bench"`
+	R03 Route `route:"GET /r03/:id" group:"bench" constraints:"id=int"`
+	R04 Route `route:"GET /r04/:id" group:"bench"`
+	R05 Route `route:"GET /r05/:id" group:"bench"`
+	R06 Route `route:"GET /r06/:id" group:"bench" timeout:"5s"`
+	R07 Route `route:"GET /r07/:id" group:"bench" meta:"scope=bench"`
+	R08 Route `route:"GET /r08/:id" group:"bench" constraints:"id=int"`
+	R09 Route `route:"GET /r09/:id" group:"bench"`
+	R10 Route `route:"GET /r10/:id" group:"bench"`
+	R11 Route `route:"GET /r11/:id" group:"bench" timeout:"5s"`
+	R12 Route `route:"GET /r12/:id" group:"bench" meta:"scope=bench"`
+	R13 Route `route:"GET /r13/:id" group:"bench" constraints:"id=int"`
+	R14 Route `route:"GET /r14/:id" group:"bench"`
+	R15 Route `route:"GET /r15/:id" group:"bench"`
+	R16 Route `route:"GET /r16/:id" group:"bench" timeout:"5s"`
+	R17 Route `route:"GET /r17/:id" group:"bench" meta:"scope=bench"`
+	R18 Route `route:"GET /r18/:id" group:"bench" constraints:"id=int"`
+	R19 Route `route:"GET /r19/:id" group:"bench"`
+	R20 Route `route:"GET /r20/:id" group:"bench"`
+	R21 Route `route:"GET /r21/:id" group:"bench" timeout:"5s"`
+	R22 Route `route:"GET /r22/:id" group:"bench" meta:"scope=bench"`
+	R23 Route `route:"GET /r23/:id" group:"bench" constraints:"id=int"`
+	R24 Route `route:"GET /r24/:id" group:"bench"`
+	R25 Route `route:"GET /r25/:id" group:"bench"`
+	R26 Route `route:"GET /r26/:id" group:"bench" timeout:"5s"`
+	R27 Route `route:"GET /r27/:id" group:"bench" meta:"scope=bench"`
+	R28 Route `route:"GET /r28/:id" group:"bench" constraints:"id=int"`
+	R29 Route `route:"GET /r29/:id" group:"bench"`
+	R30 Route `route:"GET /r30/:id" group:"bench"`
+	R31 Route `route:"GET /r31/:id" group:"bench" timeout:"5s"`
+	R32 Route `route:"GET /r32/:id" group:"bench" meta:"scope=bench"`
+	R33 Route `route:"GET /r33/:id" group:"bench" constraints:"id=int"`
+	R34 Route `route:"GET /r34/:id" group:"bench"`
+	R35 Route `route:"GET /r35/:id" group:"bench"`
+	R36 Route `route:"GET /r36/:id" group:"bench" timeout:"5s"`
+	R37 Route `route:"GET /r37/:id" group:"bench" meta:"scope=bench"`
+	R38 Route `route:"GET /r38/:id" group:"bench" constraints:"id=int"`
+	R39 Route `route:"GET /r39/:id" group:"bench"`
+	R40 Route `route:"GET /r40/:id" group:"bench"`
+	R41 Route `route:"GET /r41/:id" group:"bench" timeout:"5s"`
+	R42 Route `route:"GET /r42/:id" group:"bench" meta:"scope=bench"`
+	R43 Route `route:"GET /r43/:id" group:"bench" constraints:"id=int"`
+	R44 Route `route:"GET /r44/:id" group:"bench"`
+	R45 Route `route:"GET /r45/:id" group:"bench"`
+	R46 Route `route:"GET /r46/:id" group:"bench" timeout:"5s"`
+	R47 Route `route:"GET /r47/:id" group:"bench" meta:"scope=bench"`
+	R48 Route `route:"GET /r48/:id" group:"bench" constraints:"id=int"`
+	R49 Route `route:"GET /r49/:id" group:"bench"`
+}
+
+type benchHandler struct {
+	benchHandlerDescriber
+}
+
+func (h *benchHandler) BenchAuth(ctx *gin.Context) {
+	ctx.Next()
+}
+
+func (h *benchHandler) R00(ctx *gin.Context) {}
+
+func (h *benchHandler) R01(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R02(ctx *gin.Context) {}
+
+func (h *benchHandler) R03(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R04(ctx *gin.Context) {}
+
+func (h *benchHandler) R05(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R06(ctx *gin.Context) {}
+
+func (h *benchHandler) R07(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R08(ctx *gin.Context) {}
+
+func (h *benchHandler) R09(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R10(ctx *gin.Context) {}
+
+func (h *benchHandler) R11(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R12(ctx *gin.Context) {}
+
+func (h *benchHandler) R13(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R14(ctx *gin.Context) {}
+
+func (h *benchHandler) R15(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R16(ctx *gin.Context) {}
+
+func (h *benchHandler) R17(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R18(ctx *gin.Context) {}
+
+func (h *benchHandler) R19(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R20(ctx *gin.Context) {}
+
+func (h *benchHandler) R21(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R22(ctx *gin.Context) {}
+
+func (h *benchHandler) R23(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R24(ctx *gin.Context) {}
+
+func (h *benchHandler) R25(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R26(ctx *gin.Context) {}
+
+func (h *benchHandler) R27(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R28(ctx *gin.Context) {}
+
+func (h *benchHandler) R29(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R30(ctx *gin.Context) {}
+
+func (h *benchHandler) R31(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R32(ctx *gin.Context) {}
+
+func (h *benchHandler) R33(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R34(ctx *gin.Context) {}
+
+func (h *benchHandler) R35(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R36(ctx *gin.Context) {}
+
+func (h *benchHandler) R37(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R38(ctx *gin.Context) {}
+
+func (h *benchHandler) R39(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R40(ctx *gin.Context) {}
+
+func (h *benchHandler) R41(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R42(ctx *gin.Context) {}
+
+func (h *benchHandler) R43(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R44(ctx *gin.Context) {}
+
+func (h *benchHandler) R45(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R46(ctx *gin.Context) {}
+
+func (h *benchHandler) R47(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+func (h *benchHandler) R48(ctx *gin.Context) {}
+
+func (h *benchHandler) R49(ctx context.Context, req *benchRequest) (*benchResponse, error) {
+	return &benchResponse{ID: req.ID}, nil
+}
+
+// benchLogger is a Logger dropping every message, so that registration is measured without printing.
+type benchLogger struct{}
+
+func (benchLogger) Info(string, ...any)  {}
+func (benchLogger) Debug(string, ...any) {}
+func (benchLogger) Error(string, ...any) {}
+func (benchLogger) Panic(string, ...any) {}
+func (benchLogger) Warn(string, ...any)  {}
+
+// newBenchHandlers creates n handlers of benchHandler, each under its own path prefix so that their routes
+// do not collide.
+func newBenchHandlers(b *testing.B, n int) []*Handler {
+	b.Helper()
+
+	handlers := make([]*Handler, n)
+	for i := range handlers {
+		handler, err := AsHandler(&benchHandler{})
+		if err != nil {
+			b.Fatalf("failed to create handler: %v", err)
+		}
+
+		prefix := fmt.Sprintf("/h%d", i)
+		for _, route := range handler.routes {
+			route.path = prefix + route.path
+		}
+
+		for _, route := range handler.casualRoutes {
+			route.path = prefix + route.path
+		}
+
+		handlers[i] = handler
+	}
+
+	return handlers
+}
+
+// BenchmarkAsHandler measures the reflective scan of a struct with 50 routes, which is cached per type after
+// the first call.
+func BenchmarkAsHandler(b *testing.B) {
+	b.ReportAllocs()
+
+	for range b.N {
+		if _, err := AsHandler(&benchHandler{}); err != nil {
+			b.Fatalf("failed to create handler: %v", err)
+		}
+	}
+}
+
+// BenchmarkNew measures registering the routes of 1, 10 and 100 handlers of 50 routes each.
+func BenchmarkNew(b *testing.B) {
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("handlers=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for range b.N {
+				b.StopTimer()
+				handlers := newBenchHandlers(b, n)
+				b.StartTimer()
+
+				engine, err := New(handlers, WithLogger(benchLogger{}))
+				if err != nil {
+					b.Fatalf("failed to create engine: %v", err)
+				}
+
+				if count := engine.RoutesCount(); count != n*50 {
+					b.Fatalf("routes count = %d, want %d", count, n*50)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package httpbara
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gopybara/httpbara/casual"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// unknownFieldPrefix is the prefix of the error returned by json.Decoder for unknown fields.
+const unknownFieldPrefix = "json: unknown field "
+
+// multipartMemory is the number of bytes of a multipart body kept in memory, the rest is stored in temporary
+// files. It matches the limit of Gin's multipart binding.
+const multipartMemory = 32 << 20
+
+// decodeJSON decodes the JSON request body into obj like Gin's JSON binding, without validating it.
+// With strict, fields that obj does not declare are rejected with a 400 casual error naming the offending field,
+// see WithDisallowUnknownFields.
+func decodeJSON(ctx *gin.Context, obj interface{}, strict bool) error {
+	if ctx.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+
+	decoder := json.NewDecoder(ctx.Request.Body)
+	if binding.EnableDecoderUseNumber {
+		decoder.UseNumber()
+	}
+
+	if strict || binding.EnableDecoderDisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(obj); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), unknownFieldPrefix); ok && strict {
+			field = strings.Trim(field, `"`)
+
+			return casual.NewHTTPErrorWithDetails(http.StatusBadRequest, "unknown field "+field, &casual.HttpErrorField{
+				Field: field,
+				Issue: "Unknown field",
+			})
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// decodeXML decodes the XML request body into obj like Gin's XML binding, without validating it.
+func decodeXML(ctx *gin.Context, obj interface{}) error {
+	if ctx.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+
+	return xml.NewDecoder(ctx.Request.Body).Decode(obj)
+}
+
+// decodeYAML decodes the YAML request body into obj like Gin's YAML binding, without validating it.
+func decodeYAML(ctx *gin.Context, obj interface{}) error {
+	if ctx.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+
+	return yaml.NewDecoder(ctx.Request.Body).Decode(obj)
+}
+
+// decodeTOML decodes the TOML request body into obj like Gin's TOML binding, without validating it.
+func decodeTOML(ctx *gin.Context, obj interface{}) error {
+	if ctx.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+
+	return toml.NewDecoder(ctx.Request.Body).Decode(obj)
+}
+
+// decodeForm maps the query and the form body into the fields of obj tagged `form:"name"` like Gin's form binding,
+// without validating it.
+func decodeForm(ctx *gin.Context, obj interface{}) error {
+	if err := ctx.Request.ParseForm(); err != nil {
+		return err
+	}
+
+	if err := ctx.Request.ParseMultipartForm(multipartMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+
+	return binding.MapFormWithTag(obj, ctx.Request.Form, "form")
+}
+
+// decodeMultipartForm maps the values of a multipart body into the fields of obj tagged `form:"name"` like Gin's
+// multipart binding, without validating it. Files are set into `*multipart.FileHeader` and `[]*multipart.FileHeader`
+// fields of obj itself; nested structs only receive values.
+func decodeMultipartForm(ctx *gin.Context, obj interface{}) error {
+	if err := ctx.Request.ParseMultipartForm(multipartMemory); err != nil {
+		return err
+	}
+
+	if err := binding.MapFormWithTag(obj, ctx.Request.MultipartForm.Value, "form"); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(obj).Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		} else if name == "" {
+			name = field.Name
+		}
+
+		files := ctx.Request.MultipartForm.File[name]
+		if len(files) == 0 {
+			continue
+		}
+
+		switch field.Type {
+		case reflect.TypeOf(files[0]):
+			rv.Field(i).Set(reflect.ValueOf(files[0]))
+		case reflect.TypeOf(files):
+			rv.Field(i).Set(reflect.ValueOf(files))
+		}
+	}
+
+	return nil
+}
+
+// bindUri maps the path parameters of the matched route into the fields of obj tagged `uri:"name"`.
+// A path parameter that cannot be converted to its field type results in a 400 casual error.
+// Unlike ctx.ShouldBindUri it does not validate obj: validation runs once the body has been bound as well,
+// so required fields bound from different sources do not fail each other's validation.
+func bindUri(ctx *gin.Context, obj interface{}) error {
+	if len(ctx.Params) == 0 {
+		return nil
+	}
+
+	params := make(map[string][]string, len(ctx.Params))
+	for _, param := range ctx.Params {
+		params[param.Key] = []string{param.Value}
+	}
+
+	if err := binding.MapFormWithTag(obj, params, "uri"); err != nil {
+		return casual.NewHTTPErrorFromError(http.StatusBadRequest, err)
+	}
+
+	return nil
+}
+
+// bindBracketQuery maps query parameters in bracket notation into nested fields of obj: `filter[status]=active`
+// sets the field tagged `form:"status"` of the struct field tagged `form:"filter"`, or the "status" key of a
+// map[string]string (or map[string][]string) field tagged `form:"filter"`. Structs can be nested further,
+// e.g. `filter[created][from]`. Validation runs once the regular binding has completed.
+// A value that cannot be converted to its field type results in a 400 casual error.
+func bindBracketQuery(ctx *gin.Context, obj interface{}) error {
+	if !strings.Contains(ctx.Request.URL.RawQuery, "[") && !strings.Contains(ctx.Request.URL.RawQuery, "%5B") {
+		return nil
+	}
+
+	if err := mapBracketForm(reflect.ValueOf(obj).Elem(), ctx.Request.URL.Query()); err != nil {
+		return casual.NewHTTPErrorFromError(http.StatusBadRequest, err)
+	}
+
+	return nil
+}
+
+// mapBracketForm maps the bracketed keys of form into the fields of the struct rv.
+func mapBracketForm(rv reflect.Value, form map[string][]string) error {
+	nested := splitBracketForm(form)
+	if len(nested) == 0 {
+		return nil
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			if err := mapBracketForm(fv, form); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		} else if name == "" {
+			name = field.Name
+		}
+
+		values, ok := nested[name]
+		if !ok {
+			continue
+		}
+
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+
+			fv = fv.Elem()
+		}
+
+		switch fv.Kind() {
+		case reflect.Struct:
+			if err := binding.MapFormWithTag(fv.Addr().Interface(), values, "form"); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+
+			if err := mapBracketForm(fv, values); err != nil {
+				return err
+			}
+		case reflect.Map:
+			mapBracketValues(fv, values)
+		}
+	}
+
+	return nil
+}
+
+// mapBracketValues stores the values into a map[string]string or map[string][]string field.
+// Other map types and keys nested further are ignored.
+func mapBracketValues(fv reflect.Value, values map[string][]string) {
+	if fv.Type().Key().Kind() != reflect.String {
+		return
+	}
+
+	elemType := fv.Type().Elem()
+	if elemType.Kind() != reflect.String &&
+		!(elemType.Kind() == reflect.Slice && elemType.Elem().Kind() == reflect.String) {
+		return
+	}
+
+	if fv.IsNil() {
+		fv.Set(reflect.MakeMap(fv.Type()))
+	}
+
+	for key, vals := range values {
+		if strings.Contains(key, "[") || len(vals) == 0 {
+			continue
+		}
+
+		elem := reflect.New(elemType).Elem()
+		if elemType.Kind() == reflect.String {
+			elem.SetString(vals[0])
+		} else {
+			elem.Set(reflect.ValueOf(vals).Convert(elemType))
+		}
+
+		fv.SetMapIndex(reflect.ValueOf(key).Convert(fv.Type().Key()), elem)
+	}
+}
+
+// splitBracketForm groups bracketed keys by their prefix, stripping the first bracket pair:
+// `filter[status]` becomes "status" and `filter[created][from]` becomes "created[from]" under "filter".
+func splitBracketForm(form map[string][]string) map[string]map[string][]string {
+	nested := make(map[string]map[string][]string)
+
+	for key, values := range form {
+		open := strings.Index(key, "[")
+		if open <= 0 {
+			continue
+		}
+
+		size := strings.Index(key[open:], "]")
+		if size < 0 {
+			continue
+		}
+
+		prefix := key[:open]
+		inner := key[open+1:open+size] + key[open+size+1:]
+		if inner == "" {
+			continue
+		}
+
+		if nested[prefix] == nil {
+			nested[prefix] = make(map[string][]string)
+		}
+		nested[prefix][inner] = values
+	}
+
+	return nested
+}
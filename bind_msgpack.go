@@ -0,0 +1,25 @@
+//go:build !nomsgpack
+
+package httpbara
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ugorji/go/codec"
+)
+
+// decodeBody decodes the request body into obj with binding b, without validating it. MessagePack bodies are
+// decoded here since Gin's binding validates them with its process-wide validator; protocol buffers are left
+// to Gin, which does not validate them.
+func decodeBody(ctx *gin.Context, obj interface{}, b binding.Binding) error {
+	if b != binding.MsgPack {
+		return ctx.ShouldBindWith(obj, b)
+	}
+
+	if ctx.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+
+	return codec.NewDecoder(ctx.Request.Body, new(codec.MsgpackHandle)).Decode(obj)
+}
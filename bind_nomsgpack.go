@@ -0,0 +1,14 @@
+//go:build nomsgpack
+
+package httpbara
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// decodeBody decodes the request body into obj with binding b. Built without MessagePack support, the only
+// binding left is protocol buffers, which Gin does not validate.
+func decodeBody(ctx *gin.Context, obj interface{}, b binding.Binding) error {
+	return ctx.ShouldBindWith(obj, b)
+}
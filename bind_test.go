@@ -0,0 +1,247 @@
+package httpbara_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gopybara/httpbara"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type strictAddress struct {
+	City string `json:"city"`
+}
+
+type strictRequest struct {
+	Name    string         `json:"name"`
+	Address *strictAddress `json:"address"`
+}
+
+type strictHandlerDescriber struct {
+	Create httpbara.Route `route:"POST /users"`
+}
+
+type strictHandler struct {
+	strictHandlerDescriber
+}
+
+func (h *strictHandler) Create(ctx context.Context, req *strictRequest) (*strictRequest, error) {
+	return req, nil
+}
+
+func TestWithDisallowUnknownFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		strict bool
+		body   string
+		status int
+		field  string
+	}{
+		{name: "known fields", strict: true, body: `{"name":"bara","address":{"city":"Riga"}}`, status: http.StatusOK},
+		{name: "unknown field", strict: true, body: `{"name":"bara","role":"admin"}`, status: http.StatusBadRequest, field: "role"},
+		{
+			name:   "unknown nested field",
+			strict: true,
+			body:   `{"name":"bara","address":{"city":"Riga","zip":"1010"}}`,
+			status: http.StatusBadRequest,
+			field:  "zip",
+		},
+		{name: "unknown field allowed", body: `{"name":"bara","role":"admin"}`, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []httpbara.ParamsCb
+			if tt.strict {
+				opts = append(opts, httpbara.WithDisallowUnknownFields())
+			}
+
+			h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &strictHandler{})}, opts...)
+
+			rec := serve(h, http.MethodPost, "/users", strings.NewReader(tt.body), map[string]string{
+				"Content-Type": "application/json",
+				"Accept":       "application/json",
+			})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.field == "" {
+				return
+			}
+
+			var resp casual.HttpErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if resp.Error == nil || !strings.Contains(resp.Error.Message, tt.field) {
+				t.Fatalf("error = %+v, want a message naming %q", resp.Error, tt.field)
+			}
+
+			if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != tt.field {
+				t.Fatalf("details = %+v, want the field %q", resp.Error.Details, tt.field)
+			}
+		})
+	}
+}
+
+type searchRange struct {
+	From int `form:"from" json:"from"`
+	To   int `form:"to" json:"to" binding:"omitempty,gtefield=From"`
+}
+
+type searchFilter struct {
+	Status  string       `form:"status" json:"status" binding:"omitempty,oneof=active archived"`
+	Tags    []string     `form:"tag" json:"tags"`
+	Created *searchRange `form:"created" json:"created"`
+}
+
+type searchRequest struct {
+	Filter searchFilter      `form:"filter" json:"filter"`
+	Labels map[string]string `form:"labels" json:"labels"`
+	Page   int               `form:"page" json:"page"`
+}
+
+type searchHandlerDescriber struct {
+	Search httpbara.Route `route:"GET /search"`
+}
+
+type searchHandler struct {
+	searchHandlerDescriber
+}
+
+func (h *searchHandler) Search(ctx context.Context, req *searchRequest) (*searchRequest, error) {
+	return req, nil
+}
+
+func TestBracketQueryBinding(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   searchRequest
+	}{
+		{
+			name:   "nested struct",
+			query:  "filter[status]=active&filter[tag]=a&filter[tag]=b&page=2",
+			status: http.StatusOK,
+			want:   searchRequest{Filter: searchFilter{Status: "active", Tags: []string{"a", "b"}}, Page: 2},
+		},
+		{
+			name:   "deeper nesting",
+			query:  "filter[created][from]=10&filter[created][to]=20",
+			status: http.StatusOK,
+			want:   searchRequest{Filter: searchFilter{Created: &searchRange{From: 10, To: 20}}},
+		},
+		{
+			name:   "encoded brackets",
+			query:  "filter%5Bstatus%5D=archived",
+			status: http.StatusOK,
+			want:   searchRequest{Filter: searchFilter{Status: "archived"}},
+		},
+		{
+			name:   "map",
+			query:  "labels[team]=core&labels[env]=prod",
+			status: http.StatusOK,
+			want:   searchRequest{Labels: map[string]string{"team": "core", "env": "prod"}},
+		},
+		{name: "invalid value", query: "filter[created][from]=soon", status: http.StatusBadRequest},
+		{name: "invalid status", query: "filter[status]=deleted", status: http.StatusUnprocessableEntity},
+		{name: "invalid range", query: "filter[created][from]=20&filter[created][to]=10", status: http.StatusUnprocessableEntity},
+	}
+
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &searchHandler{})})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, "/search?"+tt.query, nil, map[string]string{"Accept": "application/json"})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data searchRequest `json:"data"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if !reflect.DeepEqual(resp.Data, tt.want) {
+				t.Fatalf("bound request = %+v, want %+v", resp.Data, tt.want)
+			}
+		})
+	}
+}
+
+type pageRequest struct {
+	Page  int      `form:"page" json:"page"`
+	Limit int      `form:"limit" json:"limit" binding:"omitempty,max=100"`
+	Sort  []string `form:"sort" json:"sort"`
+}
+
+type pageHandlerDescriber struct {
+	List   httpbara.Route `route:"GET /pages"`
+	Delete httpbara.Route `route:"DELETE /pages"`
+}
+
+type pageHandler struct {
+	pageHandlerDescriber
+}
+
+func (h *pageHandler) List(ctx context.Context, req pageRequest) (*pageRequest, error) {
+	return &req, nil
+}
+
+func (h *pageHandler) Delete(ctx context.Context, req *pageRequest) (*pageRequest, error) {
+	return req, nil
+}
+
+func TestQueryBinding(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		status int
+		want   pageRequest
+	}{
+		{name: "GET", method: http.MethodGet, query: "page=2&limit=10", status: http.StatusOK, want: pageRequest{Page: 2, Limit: 10}},
+		{name: "DELETE", method: http.MethodDelete, query: "page=3&limit=5", status: http.StatusOK, want: pageRequest{Page: 3, Limit: 5}},
+		{name: "repeated values", method: http.MethodGet, query: "sort=name&sort=-id", status: http.StatusOK, want: pageRequest{Sort: []string{"name", "-id"}}},
+		{name: "no query", method: http.MethodGet, status: http.StatusOK},
+		{name: "validation", method: http.MethodGet, query: "page=2&limit=1000", status: http.StatusUnprocessableEntity},
+	}
+
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &pageHandler{})})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, tt.method, "/pages?"+tt.query, nil, map[string]string{"Accept": "application/json"})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data pageRequest `json:"data"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if !reflect.DeepEqual(resp.Data, tt.want) {
+				t.Fatalf("bound request = %+v, want %+v", resp.Data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package httpbara
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"strings"
+)
+
+var (
+	ErrBodyDumpSinkNotSet = errors.New("body dump sink is not set")
+)
+
+// BodyDumpSink receives the request and response bodies captured by the body dump middleware, with the ID set by
+// the request ID middleware (empty without it). It is called synchronously once the request is handled.
+type BodyDumpSink func(reqID string, req, resp []byte)
+
+type bodyDumpOpts struct {
+	maxBodySize int64
+}
+
+type BodyDumpOpt func(*bodyDumpOpts)
+
+// WithBodyDumpMaxSize limits the number of bytes captured of each body (64 KiB by default), 0 captures none.
+// Longer bodies are truncated in the dump, while the handler and the client still get them in full.
+func WithBodyDumpMaxSize(size int64) BodyDumpOpt {
+	return func(opts *bodyDumpOpts) {
+		opts.maxBodySize = size
+	}
+}
+
+type bodyDumpMiddlewareDescriber struct {
+	Middleware Middleware `middleware:"bodydump"`
+}
+
+type bodyDumpMiddleware struct {
+	bodyDumpMiddlewareDescriber
+
+	sink BodyDumpSink
+	opts bodyDumpOpts
+}
+
+// NewBodyDumpMiddleware creates a middleware named "bodydump" that captures the request and response bodies and
+// passes them to sink, e.g. to reproduce client bugs on a staging environment. It is meant for debugging only:
+// bodies are captured as they are, without redaction.
+//
+// The request body is buffered and restored, so handlers bind it as usual. Streaming responses, flushed by the
+// handler or sent as `text/event-stream`, are not captured and reach the sink as nil.
+//
+// **Example:**
+// ```go
+//
+//	dump, _ := httpbara.NewBodyDumpMiddleware(func(reqID string, req, resp []byte) {
+//	    log.Debug("body dump", "requestId", reqID, "request", string(req), "response", string(resp))
+//	}, httpbara.WithBodyDumpMaxSize(16<<10))
+//	engine, _ := httpbara.New(handlers, httpbara.WithRootMiddlewares(requestID, dump))
+//
+// ```
+func NewBodyDumpMiddleware(sink BodyDumpSink, opts ...BodyDumpOpt) (*Handler, error) {
+	if sink == nil {
+		return nil, ErrBodyDumpSinkNotSet
+	}
+
+	bdm := bodyDumpMiddleware{
+		sink: sink,
+		opts: bodyDumpOpts{
+			maxBodySize: 64 << 10,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&bdm.opts)
+	}
+
+	if bdm.opts.maxBodySize < 0 {
+		return nil, fmt.Errorf("invalid body dump max size: %d", bdm.opts.maxBodySize)
+	}
+
+	return AsHandler(&bdm)
+}
+
+func (bdm *bodyDumpMiddleware) Middleware(ctx *gin.Context) {
+	var req []byte
+	if ctx.Request.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, bdm.opts.maxBodySize+1))
+		req = body[:min(int64(len(body)), bdm.opts.maxBodySize)]
+
+		// Replay the buffered bytes followed by the unread remainder of the body.
+		ctx.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: io.MultiReader(bytes.NewReader(body), &errReader{err: err, r: ctx.Request.Body}),
+			Closer: ctx.Request.Body,
+		}
+	}
+
+	writer := &bodyDumpWriter{
+		ResponseWriter: ctx.Writer,
+		maxBodySize:    bdm.opts.maxBodySize,
+	}
+	ctx.Writer = writer
+
+	ctx.Next()
+
+	ctx.Writer = writer.ResponseWriter
+
+	var resp []byte
+	if !writer.streaming {
+		resp = writer.body.Bytes()
+	}
+
+	bdm.sink(RequestIDFromContext(ctx), req, resp)
+}
+
+// bodyDumpWriter captures up to maxBodySize bytes of the response body, unless the response is streamed.
+type bodyDumpWriter struct {
+	gin.ResponseWriter
+
+	body        bytes.Buffer
+	maxBodySize int64
+	streaming   bool
+}
+
+func (w *bodyDumpWriter) capture(data []byte) {
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		w.streaming = true
+	}
+
+	if w.streaming {
+		return
+	}
+
+	if remaining := w.maxBodySize - int64(w.body.Len()); remaining > 0 {
+		w.body.Write(data[:min(int64(len(data)), remaining)])
+	}
+}
+
+func (w *bodyDumpWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyDumpWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyDumpWriter) Flush() {
+	w.streaming = true
+	w.ResponseWriter.Flush()
+}
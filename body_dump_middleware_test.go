@@ -0,0 +1,148 @@
+package httpbara_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type streamHandlerDescriber struct {
+	Events httpbara.Route `route:"GET /events"`
+	Flush  httpbara.Route `route:"GET /flush"`
+}
+
+type streamHandler struct {
+	streamHandlerDescriber
+}
+
+func (h *streamHandler) Events(ctx *gin.Context) {
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.String(http.StatusOK, "data: hello\n\n")
+}
+
+func (h *streamHandler) Flush(ctx *gin.Context) {
+	ctx.String(http.StatusOK, "hello")
+	ctx.Writer.Flush()
+}
+
+func TestBodyDumpMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []httpbara.BodyDumpOpt
+		method   string
+		target   string
+		body     string
+		wantBody string
+		wantReq  string
+		wantResp *string
+	}{
+		{
+			name:     "full bodies",
+			method:   http.MethodPost,
+			target:   "/echo",
+			body:     "hello",
+			wantBody: "hello",
+			wantReq:  "hello",
+			wantResp: ptr("hello"),
+		},
+		{
+			name:     "truncated bodies",
+			opts:     []httpbara.BodyDumpOpt{httpbara.WithBodyDumpMaxSize(3)},
+			method:   http.MethodPost,
+			target:   "/echo",
+			body:     "hello",
+			wantBody: "hello",
+			wantReq:  "hel",
+			wantResp: ptr("hel"),
+		},
+		{
+			name:     "nothing captured",
+			opts:     []httpbara.BodyDumpOpt{httpbara.WithBodyDumpMaxSize(0)},
+			method:   http.MethodPost,
+			target:   "/echo",
+			body:     "hello",
+			wantBody: "hello",
+			wantResp: ptr(""),
+		},
+		{
+			name:     "server-sent events",
+			method:   http.MethodGet,
+			target:   "/events",
+			wantBody: "data: hello\n\n",
+		},
+		{
+			name:     "flushed response",
+			method:   http.MethodGet,
+			target:   "/flush",
+			wantBody: "hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				calls        int
+				gotID        string
+				gotReq, resp []byte
+			)
+			dump, err := httpbara.NewBodyDumpMiddleware(func(reqID string, req, res []byte) {
+				calls++
+				gotID, gotReq, resp = reqID, req, res
+			}, tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to create middleware: %v", err)
+			}
+
+			requestID, err := httpbara.NewRequestIDMiddleware()
+			if err != nil {
+				t.Fatalf("failed to create request ID middleware: %v", err)
+			}
+
+			h := newTestEngine(t, []*httpbara.Handler{
+				mustHandler(t, &echoHandler{}),
+				mustHandler(t, &streamHandler{}),
+			}, httpbara.WithRootMiddlewares(requestID, dump))
+
+			rec := serve(h, tt.method, tt.target, strings.NewReader(tt.body), map[string]string{httpbara.DefaultRequestIDHeader: "req-1"})
+			if rec.Code != http.StatusOK || rec.Body.String() != tt.wantBody {
+				t.Fatalf("response = %d %q, want %d %q", rec.Code, rec.Body.String(), http.StatusOK, tt.wantBody)
+			}
+
+			if calls != 1 {
+				t.Fatalf("sink called %d times, want 1", calls)
+			}
+
+			if gotID != "req-1" {
+				t.Fatalf("request ID = %q, want %q", gotID, "req-1")
+			}
+
+			if string(gotReq) != tt.wantReq {
+				t.Fatalf("request body = %q, want %q", gotReq, tt.wantReq)
+			}
+
+			switch {
+			case tt.wantResp == nil && resp != nil:
+				t.Fatalf("response body = %q, want nil", resp)
+			case tt.wantResp != nil && string(resp) != *tt.wantResp:
+				t.Fatalf("response body = %q, want %q", resp, *tt.wantResp)
+			}
+		})
+	}
+}
+
+func TestNewBodyDumpMiddlewareInvalid(t *testing.T) {
+	if _, err := httpbara.NewBodyDumpMiddleware(nil); err != httpbara.ErrBodyDumpSinkNotSet {
+		t.Fatalf("err = %v, want %v", err, httpbara.ErrBodyDumpSinkNotSet)
+	}
+
+	sink := func(string, []byte, []byte) {}
+	if _, err := httpbara.NewBodyDumpMiddleware(sink, httpbara.WithBodyDumpMaxSize(-1)); err == nil {
+		t.Fatal("expected an error for a negative max size")
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
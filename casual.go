@@ -1,17 +1,40 @@
 package httpbara
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gopybara/httpbara/casual"
+	"net/http"
 	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidCasualRequest is returned by AsHandler when a casual handler declares a request
+	// parameter that is neither a struct nor a pointer to a struct.
+	ErrInvalidCasualRequest = errors.New("casual handler request must be a struct or a pointer to a struct")
+
+	// ErrInvalidCasualResponse is returned by AsHandler when a casual handler returns something other than
+	// an error, (data, error) or (data, http.Header, error).
+	ErrInvalidCasualResponse = errors.New("casual handler must return error, (data, error) or (data, http.Header, error)")
 )
 
 type casualRoute struct {
+	name        string
 	middlewares []string
 	group       string
 	method      string
 	path        string
 	handler     *casualHandler
+	constraints []*paramConstraint
+	consumes    []string
+	metadata    map[string]string
+	status      int
+
+	timeout        time.Duration
+	invalidTimeout string
 }
 
 type casualHandler struct {
@@ -19,24 +42,69 @@ type casualHandler struct {
 	rm *reflect.Method
 }
 
+// isCasualHandler reports whether t takes the parameters of a casual handler method: a context and a request,
+// or only a context for endpoints without request, e.g. `func(ctx context.Context) (*Status, error)`.
+// Its results are checked by validateCasualResponse once a route refers to the method, so that an unsupported
+// result list fails AsHandler instead of leaving the route unregistered.
 func isCasualHandler(t reflect.Type) bool {
-	if t.NumIn() != 3 ||
-		t.NumOut() < 1 {
+	if t.NumIn() != 2 && t.NumIn() != 3 {
 		return false
 	}
 
-	if t.In(1).String() != reflect.TypeOf((*gin.Context)(nil)).String() && t.In(1).String() != "context.Context" {
-		return false
+	return t.In(1).String() == reflect.TypeOf((*gin.Context)(nil)).String() || t.In(1).String() == "context.Context"
+}
+
+// requestType returns the type of the request parameter of the handler, nil if it takes only a context.
+func (h *casualHandler) requestType() reflect.Type {
+	if h.rm.Type.NumIn() < 3 {
+		return nil
 	}
 
+	return h.rm.Type.In(2)
+}
+
+// validateCasualResponse checks that a casual handler returns an error, data and an error,
+// or data, response headers and an error.
+func validateCasualResponse(handler *casualHandler) error {
+	t := handler.rm.Type
+
+	valid := false
 	switch t.NumOut() {
 	case 1:
-		return t.Out(0).String() == "error"
+		valid = t.Out(0).String() == "error"
 	case 2:
-		return t.Out(1).String() == "error"
-	default:
-		return false
+		valid = t.Out(1).String() == "error"
+	case 3:
+		valid = t.Out(1) == reflect.TypeOf(http.Header{}) && t.Out(2).String() == "error"
+	}
+
+	if !valid {
+		results := make([]string, t.NumOut())
+		for i := range results {
+			results[i] = t.Out(i).String()
+		}
+
+		return fmt.Errorf("%w: %s returns (%s)", ErrInvalidCasualResponse, handler.rm.Name, strings.Join(results, ", "))
 	}
+
+	return nil
+}
+
+// validateCasualRequest checks that the request parameter of a casual handler can be bound,
+// i.e. that it is a struct or a pointer to a struct. Handlers without request parameter are valid.
+func validateCasualRequest(handler *casualHandler) error {
+	reqType := handler.requestType()
+	if reqType == nil {
+		return nil
+	} else if reqType.Kind() == reflect.Ptr {
+		reqType = reqType.Elem()
+	}
+
+	if reqType.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %s has request type %s", ErrInvalidCasualRequest, handler.rm.Name, handler.rm.Type.In(2))
+	}
+
+	return nil
 }
 
 // Basic casual responses
@@ -47,3 +115,15 @@ func defaultCasualErrorResponder(err error, opts ...casual.HttpResponseParamsCb)
 func defaultCasualResponder[T any](value T, opts ...casual.HttpResponseParamsCb) (int, any) {
 	return casual.NewHTTPResponse[T](&value, opts...)
 }
+
+// defaultCasualListResponder renders a slice returned by a casual handler with the list envelope.
+func defaultCasualListResponder(data any, opts ...casual.HttpResponseParamsCb) (int, any) {
+	rv := reflect.ValueOf(data)
+
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+
+	return casual.NewHTTPListResponse(items, opts...)
+}
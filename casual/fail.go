@@ -0,0 +1,43 @@
+package casual
+
+import "github.com/gin-gonic/gin"
+
+// failureKey is the gin.Context key under which Fail stores the error.
+const failureKey = "casualFailure"
+
+// Fail aborts the request with err, which the engine renders with its casual error responder once the
+// middleware chain returns, the same way errors returned by casual handlers are rendered. This gives
+// middlewares (authentication, rate limiting, ...) the same error envelopes and content negotiation as handlers.
+//
+// The status code comes from the error: an HttpError (e.g. ErrUnauthorized or one created with
+// NewHTTPErrorFromMessage) responds with its own status, validation errors with 422, and other errors with 500.
+//
+// **Example:**
+// ```go
+//
+//	func (a *AuthMiddleware) Middleware(ctx *gin.Context) {
+//	    if ctx.GetHeader("Authorization") == "" {
+//	        casual.Fail(ctx, casual.ErrUnauthorized)
+//	        return
+//	    }
+//
+//	    ctx.Next()
+//	}
+//
+// ```
+func Fail(ctx *gin.Context, err error) {
+	ctx.Set(failureKey, err)
+	ctx.Abort()
+}
+
+// Failure returns the error passed to Fail for the request, or nil.
+func Failure(ctx *gin.Context) error {
+	value, ok := ctx.Get(failureKey)
+	if !ok {
+		return nil
+	}
+
+	err, _ := value.(error)
+
+	return err
+}
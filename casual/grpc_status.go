@@ -0,0 +1,82 @@
+package casual
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// grpcHttpStatuses maps gRPC status codes to HTTP statuses following the standard gRPC gateway mapping.
+// The keys are the numeric values of `google.golang.org/grpc/codes.Code`.
+var grpcHttpStatuses = map[uint64]int{
+	0:  http.StatusOK,                  // OK
+	1:  499,                            // Canceled
+	2:  http.StatusInternalServerError, // Unknown
+	3:  http.StatusBadRequest,          // InvalidArgument
+	4:  http.StatusGatewayTimeout,      // DeadlineExceeded
+	5:  http.StatusNotFound,            // NotFound
+	6:  http.StatusConflict,            // AlreadyExists
+	7:  http.StatusForbidden,           // PermissionDenied
+	8:  http.StatusTooManyRequests,     // ResourceExhausted
+	9:  http.StatusBadRequest,          // FailedPrecondition
+	10: http.StatusConflict,            // Aborted
+	11: http.StatusBadRequest,          // OutOfRange
+	12: http.StatusNotImplemented,      // Unimplemented
+	13: http.StatusInternalServerError, // Internal
+	14: http.StatusServiceUnavailable,  // Unavailable
+	15: http.StatusInternalServerError, // DataLoss
+	16: http.StatusUnauthorized,        // Unauthenticated
+}
+
+// grpcStatus describes a gRPC status carried by an error.
+type grpcStatus struct {
+	httpCode int
+	code     string
+	message  string
+}
+
+// findGrpcStatus walks the error chain looking for an error implementing `GRPCStatus() *status.Status`,
+// as returned by `status.Error` and gRPC clients. The method is looked up by name, so gRPC is not a
+// dependency of this package.
+func findGrpcStatus(err error) (*grpcStatus, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		method := reflect.ValueOf(err).MethodByName("GRPCStatus")
+		if !method.IsValid() ||
+			method.Type().NumIn() != 0 ||
+			method.Type().NumOut() != 1 {
+			continue
+		}
+
+		st := method.Call([]reflect.Value{})[0]
+		if st.Kind() == reflect.Ptr && st.IsNil() {
+			continue
+		}
+
+		codeMethod := st.MethodByName("Code")
+		messageMethod := st.MethodByName("Message")
+		if !codeMethod.IsValid() || codeMethod.Type().NumOut() != 1 ||
+			!messageMethod.IsValid() || messageMethod.Type().NumOut() != 1 ||
+			messageMethod.Type().Out(0).Kind() != reflect.String {
+			continue
+		}
+
+		code := codeMethod.Call([]reflect.Value{})[0]
+		if code.Kind() != reflect.Uint32 {
+			continue
+		}
+
+		httpCode, ok := grpcHttpStatuses[code.Uint()]
+		if !ok {
+			httpCode = http.StatusInternalServerError
+		}
+
+		return &grpcStatus{
+			httpCode: httpCode,
+			code:     fmt.Sprint(code.Interface()),
+			message:  messageMethod.Call([]reflect.Value{})[0].String(),
+		}, true
+	}
+
+	return nil, false
+}
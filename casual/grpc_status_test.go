@@ -0,0 +1,108 @@
+package casual
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// grpcCode mimics `codes.Code`, whose String method returns the code name.
+type grpcCode uint32
+
+func (c grpcCode) String() string {
+	names := map[grpcCode]string{3: "InvalidArgument", 5: "NotFound", 7: "PermissionDenied", 14: "Unavailable", 16: "Unauthenticated"}
+	if name, ok := names[c]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Code(%d)", uint32(c))
+}
+
+// grpcStatusValue mimics `*status.Status`.
+type grpcStatusValue struct {
+	code    grpcCode
+	message string
+}
+
+func (s *grpcStatusValue) Code() grpcCode {
+	return s.code
+}
+
+func (s *grpcStatusValue) Message() string {
+	return s.message
+}
+
+// grpcError mimics the error returned by `status.Error`.
+type grpcError struct {
+	status *grpcStatusValue
+}
+
+func (e *grpcError) Error() string {
+	return "rpc error: " + e.status.message
+}
+
+func (e *grpcError) GRPCStatus() *grpcStatusValue {
+	return e.status
+}
+
+func newGrpcError(code grpcCode, message string) error {
+	return &grpcError{status: &grpcStatusValue{code: code, message: message}}
+}
+
+func TestNewHttpErrorResponseGrpcStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    any
+		message string
+	}{
+		{name: "not found", err: newGrpcError(5, "user not found"), status: http.StatusNotFound, code: "NotFound", message: "user not found"},
+		{name: "permission denied", err: newGrpcError(7, "denied"), status: http.StatusForbidden, code: "PermissionDenied", message: "denied"},
+		{name: "invalid argument", err: newGrpcError(3, "bad id"), status: http.StatusBadRequest, code: "InvalidArgument", message: "bad id"},
+		{name: "unauthenticated", err: newGrpcError(16, "no token"), status: http.StatusUnauthorized, code: "Unauthenticated", message: "no token"},
+		{name: "unavailable", err: newGrpcError(14, "down"), status: http.StatusServiceUnavailable, code: "Unavailable", message: "down"},
+		{name: "unknown code", err: newGrpcError(42, "odd"), status: http.StatusInternalServerError, code: "Code(42)", message: "odd"},
+		{
+			name:    "wrapped",
+			err:     fmt.Errorf("loading user: %w", newGrpcError(5, "user not found")),
+			status:  http.StatusNotFound,
+			code:    "NotFound",
+			message: "user not found",
+		},
+		{
+			name:    "nil status",
+			err:     fmt.Errorf("rpc error: %w", &nilStatusError{}),
+			status:  http.StatusInternalServerError,
+			message: "rpc error: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, resp := NewHttpErrorResponse(tt.err)
+			if code != tt.status || resp.Status != tt.status {
+				t.Fatalf("status = %d (envelope %d), want %d", code, resp.Status, tt.status)
+			}
+
+			if resp.Error.Code != tt.code {
+				t.Fatalf("code = %v, want %v", resp.Error.Code, tt.code)
+			}
+
+			if resp.Error.Message != tt.message {
+				t.Fatalf("message = %q, want %q", resp.Error.Message, tt.message)
+			}
+		})
+	}
+}
+
+// nilStatusError returns a nil status, as status.FromError does for errors without one.
+type nilStatusError struct{}
+
+func (e *nilStatusError) Error() string {
+	return ""
+}
+
+func (e *nilStatusError) GRPCStatus() *grpcStatusValue {
+	return nil
+}
@@ -0,0 +1,109 @@
+package casual
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/gopybara/httpbara/common"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// Header-based meta conventions of bare responses. As bare responses carry no envelope, everything
+// that HttpResponse and HttpListResponse put next to the data is sent in headers instead:
+//
+//   - the length of a slice or array (`meta.total` of HttpResponse) is sent as `X-Total-Count`,
+//   - the cursor set via WithCursor is sent as `X-Next-Cursor`,
+//   - any other meta entry is sent as `X-Meta-<Key>` with its value formatted by fmt.Sprint,
+//   - the location set via WithLocation is sent as `Location`.
+const (
+	TotalCountHeader = "X-Total-Count"
+	NextCursorHeader = "X-Next-Cursor"
+	MetaHeaderPrefix = "X-Meta-"
+)
+
+// HttpBareResponse renders its data without an envelope: it marshals exactly as the data itself would
+// (JSON, XML and YAML), while its meta travels in response headers (see TotalCountHeader).
+type HttpBareResponse struct {
+	data     any
+	headers  map[string]string
+	location string
+}
+
+// Location returns the location of the created resource set via WithLocation, if any.
+func (r *HttpBareResponse) Location() string {
+	return r.location
+}
+
+// ResponseHeaders returns the meta headers of the response, which the casual dispatch writes onto the response.
+func (r *HttpBareResponse) ResponseHeaders() map[string]string {
+	return r.headers
+}
+
+// Data returns the data rendered as the response body.
+func (r *HttpBareResponse) Data() any {
+	return r.data
+}
+
+func (r *HttpBareResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.data)
+}
+
+func (r *HttpBareResponse) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return e.Encode(r.data)
+}
+
+func (r *HttpBareResponse) MarshalYAML() (interface{}, error) {
+	return r.data, nil
+}
+
+// NewHTTPBareResponse builds a bare response and its status code for data.
+func NewHTTPBareResponse(data any, opts ...HttpResponseParamsCb) (int, *HttpBareResponse) {
+	var params httpResponseParams
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	if params.statusCode == nil {
+		params.statusCode = common.Ptr(http.StatusOK)
+	}
+
+	headers := make(map[string]string)
+	for key, value := range params.meta {
+		headers[MetaHeaderPrefix+http.CanonicalHeaderKey(key)] = fmt.Sprint(value)
+	}
+
+	if total, ok := params.meta["total"]; ok {
+		delete(headers, MetaHeaderPrefix+"Total")
+		headers[TotalCountHeader] = fmt.Sprint(total)
+	} else if elem := reflect.ValueOf(data); elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+		headers[TotalCountHeader] = strconv.Itoa(elem.Len())
+	}
+
+	if params.cursor != nil {
+		headers[NextCursorHeader] = *params.cursor
+	}
+
+	var location string
+	if params.location != nil {
+		location = *params.location
+	}
+
+	return *params.statusCode, &HttpBareResponse{
+		data:     data,
+		headers:  headers,
+		location: location,
+	}
+}
+
+// NewHttpBareErrorResponse renders err like NewHttpErrorResponse, but without the envelope:
+// the body is the HttpError itself (`code`, `message`, `details`) and its headers are kept.
+func NewHttpBareErrorResponse(err error, opts ...HttpResponseParamsCb) (int, *HttpBareResponse) {
+	code, resp := NewHttpErrorResponse(err, opts...)
+
+	return code, &HttpBareResponse{
+		data:    resp.Error,
+		headers: resp.ResponseHeaders(),
+	}
+}
@@ -13,6 +13,15 @@ type HttpErrorResponse struct {
 	Meta   map[string]interface{} `json:"meta,omitempty" xml:"meta,omitempty"`
 }
 
+// ResponseHeaders returns the headers carried by the error, which the casual error dispatch writes onto the response.
+func (r *HttpErrorResponse) ResponseHeaders() map[string]string {
+	if r.Error == nil {
+		return nil
+	}
+
+	return r.Error.Headers
+}
+
 type HttpError struct {
 	error
 	frontendMessage *string
@@ -22,6 +31,9 @@ type HttpError struct {
 	Message string `json:"message" xml:"message"`
 
 	Details []*HttpErrorField `json:"details,omitempty" xml:"details,omitempty"`
+
+	// Headers are written onto the response by the casual error dispatch before the body.
+	Headers map[string]string `json:"-" xml:"-"`
 }
 
 func (e HttpError) GetHttpStatusCode() int {
@@ -71,6 +83,26 @@ func NewHTTPErrorFromError(httpCode int, err error, frontendMessage ...string) e
 	return httpErr
 }
 
+func NewHTTPErrorWithDetails(httpCode int, message string, details ...*HttpErrorField) error {
+	httpErr := HttpError{error: errors.New(message), httpCode: httpCode, Details: details}
+	httpErr.frontendMessage = &message
+
+	return httpErr
+}
+
+// NewHTTPErrorWithHeaders creates an error answered with httpCode and message that also sets the given
+// response headers, e.g. `WWW-Authenticate` on 401 or `Retry-After` on 429.
+func NewHTTPErrorWithHeaders(httpCode int, message string, headers map[string]string) error {
+	httpErr := HttpError{error: errors.New(message), httpCode: httpCode, Headers: headers}
+	httpErr.frontendMessage = &message
+
+	return httpErr
+}
+
+// NewHttpErrorResponse builds the error envelope and its status code for err.
+//
+// HttpError values use their own status, errors carrying a gRPC status (`GRPCStatus()`, e.g. from `status.Error`)
+// are mapped to the matching HTTP status with the gRPC code name as `code`, and validation errors respond with 422.
 func NewHttpErrorResponse(err error, opts ...HttpResponseParamsCb) (int, *HttpErrorResponse) {
 	var params httpResponseParams
 	params.statusCode = common.Ptr(http.StatusInternalServerError)
@@ -90,14 +122,18 @@ func NewHttpErrorResponse(err error, opts ...HttpResponseParamsCb) (int, *HttpEr
 
 	if errors.As(err, &httpErr) {
 		params.statusCode = common.Ptr(httpErr.GetHttpStatusCode())
-		errorMessage = err.(HttpError).GetMessage()
+		errorMessage = httpErr.GetMessage()
+	} else if st, ok := findGrpcStatus(err); ok {
+		params.statusCode = common.Ptr(st.httpCode)
+		httpErr.Code = st.code
+		errorMessage = st.message
 	} else if errors.As(err, &ve) {
 		for _, fe := range ve {
 			params.statusCode = common.Ptr(http.StatusUnprocessableEntity)
 
 			httpErr.Details = append(httpErr.Details, &HttpErrorField{
 				Field: fe.Field(),
-				Issue: getValidationErrorText(params.lang, fe),
+				Issue: translateValidationError(&params, fe),
 			})
 		}
 	}
@@ -0,0 +1,55 @@
+package casual
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNewHttpErrorResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+	}{
+		{
+			name:    "http error",
+			err:     ErrNotFound,
+			status:  http.StatusNotFound,
+			message: "not found",
+		},
+		{
+			name:    "wrapped http error",
+			err:     fmt.Errorf("loading session: %w", ErrUnauthorized),
+			status:  http.StatusUnauthorized,
+			message: "unauthorized",
+		},
+		{
+			name:    "http error without frontend message",
+			err:     NewHTTPErrorFromError(http.StatusBadGateway, errors.New("upstream timed out")),
+			status:  http.StatusBadGateway,
+			message: "upstream timed out",
+		},
+		{
+			name:    "plain error",
+			err:     errors.New("database is down"),
+			status:  http.StatusInternalServerError,
+			message: "database is down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := NewHttpErrorResponse(tt.err)
+			if status != tt.status || resp.Status != tt.status {
+				t.Fatalf("status = %d (envelope %d), want %d", status, resp.Status, tt.status)
+			}
+
+			if resp.Error.Message != tt.message {
+				t.Fatalf("message = %q, want %q", resp.Error.Message, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package casual
+
+import (
+	"github.com/gopybara/httpbara/common"
+	"net/http"
+)
+
+// HttpListResponse is the list envelope. Unlike HttpResponse, which renders a slice as `data`
+// and reports its length in `meta.total`, it always renders the items as a JSON array (never null)
+// next to their count and an optional cursor pointing to the next page.
+type HttpListResponse[T any] struct {
+	Status int                    `json:"status" xml:"status"`
+	Items  []T                    `json:"items" xml:"items"`
+	Total  int                    `json:"total" xml:"total"`
+	Cursor string                 `json:"cursor,omitempty" xml:"cursor,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty" xml:"meta,omitempty"`
+}
+
+func NewHTTPListResponse[T any](items []T, opts ...HttpResponseParamsCb) (int, *HttpListResponse[T]) {
+	var params httpResponseParams
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	if items == nil {
+		items = make([]T, 0)
+	}
+
+	var cursor string
+	if params.cursor != nil {
+		cursor = *params.cursor
+	}
+
+	if params.statusCode == nil {
+		params.statusCode = common.Ptr(http.StatusOK)
+	}
+
+	return *params.statusCode, &HttpListResponse[T]{
+		Status: *params.statusCode,
+		Items:  items,
+		Total:  len(items),
+		Cursor: cursor,
+		Meta:   params.meta,
+	}
+}
@@ -10,6 +10,13 @@ type HttpResponse[T any] struct {
 	Status int                    `json:"status" xml:"status"`
 	Data   *T                     `json:"data,omitempty" xml:"data,omitempty"`
 	Meta   map[string]interface{} `json:"meta,omitempty" xml:"meta,omitempty"`
+
+	location string
+}
+
+// Location returns the location of the created resource set via WithLocation, if any.
+func (r *HttpResponse[T]) Location() string {
+	return r.location
 }
 
 func NewHTTPResponse[T any](data *T, opts ...HttpResponseParamsCb) (int, *HttpResponse[T]) {
@@ -18,8 +25,9 @@ func NewHTTPResponse[T any](data *T, opts ...HttpResponseParamsCb) (int, *HttpRe
 		opt(&params)
 	}
 
+	// Data rendered by the casual dispatch arrives as a pointer to an interface holding the value
 	elem := reflect.ValueOf(data)
-	if elem.Kind() == reflect.Ptr {
+	for (elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface) && !elem.IsNil() {
 		elem = elem.Elem()
 	}
 
@@ -42,9 +50,15 @@ func NewHTTPResponse[T any](data *T, opts ...HttpResponseParamsCb) (int, *HttpRe
 		params.statusCode = common.Ptr(http.StatusOK)
 	}
 
+	var location string
+	if params.location != nil {
+		location = *params.location
+	}
+
 	return *params.statusCode, &HttpResponse[T]{
-		Status: *params.statusCode,
-		Data:   data,
-		Meta:   metadata,
+		Status:   *params.statusCode,
+		Data:     data,
+		Meta:     metadata,
+		location: location,
 	}
 }
@@ -1,9 +1,14 @@
 package casual
 
+import ut "github.com/go-playground/universal-translator"
+
 type httpResponseParams struct {
-	statusCode *int
-	meta       map[string]interface{}
-	lang       *string
+	statusCode  *int
+	meta        map[string]interface{}
+	lang        *string
+	cursor      *string
+	location    *string
+	translators []ut.Translator
 }
 
 type HttpResponseParamsCb func(params *httpResponseParams)
@@ -25,3 +30,42 @@ func WithMeta(meta map[string]interface{}) HttpResponseParamsCb {
 		params.meta = meta
 	}
 }
+
+func WithCursor(cursor string) HttpResponseParamsCb {
+	return func(params *httpResponseParams) {
+		params.cursor = &cursor
+	}
+}
+
+// WithLocation sets the location of a created resource, sent as the `Location` response header.
+func WithLocation(location string) HttpResponseParamsCb {
+	return func(params *httpResponseParams) {
+		params.location = &location
+	}
+}
+
+// WithTranslators sets the translators used to render validation errors with the translations registered on
+// the validator instance. The translator whose locale matches the response language is used, the first one otherwise.
+func WithTranslators(translators ...ut.Translator) HttpResponseParamsCb {
+	return func(params *httpResponseParams) {
+		params.translators = translators
+	}
+}
+
+// translator returns the translator for the response language, the first translator if none matches,
+// or nil if there are no translators.
+func (params *httpResponseParams) translator() ut.Translator {
+	if len(params.translators) == 0 {
+		return nil
+	}
+
+	if params.lang != nil {
+		for _, trans := range params.translators {
+			if trans.Locale() == *params.lang {
+				return trans
+			}
+		}
+	}
+
+	return params.translators[0]
+}
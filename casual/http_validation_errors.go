@@ -10,34 +10,112 @@ type HttpErrorField struct {
 	Issue string `json:"issue" xml:"issue"`
 }
 
+// defaultValidationLang is the language of the built-in validation messages used when the response
+// language has no translation.
+const defaultValidationLang = "en"
+
 var validationErrors = map[string]func(lang *string, fe validator.FieldError) string{
-	"required": func(lang *string, fe validator.FieldError) string {
-		return "Field is required"
-	},
-	"lte": func(lang *string, fe validator.FieldError) string {
-		return "Should be less than " + fe.Param()
-	},
-	"gte": func(lang *string, fe validator.FieldError) string {
-		return "Should be greater than " + fe.Param()
-	},
-	"oneof": func(lang *string, fe validator.FieldError) string {
-		return "Should be one of [" + strings.Join(strings.Split(fe.Param(), " "), ",") + "]"
-	},
-	"notempty": func(lang *string, fe validator.FieldError) string {
-		return "Param should not be empty"
-	},
-	"email": func(lang *string, fe validator.FieldError) string {
-		return "Param should be valid email " + fe.Param()
-	},
-	"url": func(lang *string, fe validator.FieldError) string {
-		return "Param should be valid url"
-	},
-	"min": func(lang *string, fe validator.FieldError) string {
-		return "Param should be greater than " + fe.Param()
-	},
-	"max": func(lang *string, fe validator.FieldError) string {
-		return "Param should be less than " + fe.Param()
-	},
+	"required": LocalizedValidationErrorMessageFunc(map[string]string{
+		"en": "Field is required",
+		"ru": "Поле обязательно для заполнения",
+		"es": "El campo es obligatorio",
+	}),
+	"lte": LocalizedValidationErrorMessageFunc(map[string]string{
+		"en": "Should be less than {param}",
+		"ru": "Должно быть меньше {param}",
+		"es": "Debe ser menor que {param}",
+	}),
+	"gte": LocalizedValidationErrorMessageFunc(map[string]string{
+		"en": "Should be greater than {param}",
+		"ru": "Должно быть больше {param}",
+		"es": "Debe ser mayor que {param}",
+	}),
+	"oneof": LocalizedValidationErrorMessageFunc(map[string]string{
+		"en": "Should be one of [{values}]",
+		"ru": "Должно быть одним из [{values}]",
+		"es": "Debe ser uno de [{values}]",
+	}),
+	"notempty": LocalizedValidationErrorMessageFunc(map[string]string{
+		"en": "Param should not be empty",
+		"ru": "Параметр не должен быть пустым",
+		"es": "El parámetro no debe estar vacío",
+	}),
+	"email": LocalizedValidationErrorMessageFunc(map[string]string{
+		"en": "Param should be valid email {param}",
+		"ru": "Параметр должен быть корректным email {param}",
+		"es": "El parámetro debe ser un email válido {param}",
+	}),
+	"url": LocalizedValidationErrorMessageFunc(map[string]string{
+		"en": "Param should be valid url",
+		"ru": "Параметр должен быть корректным URL",
+		"es": "El parámetro debe ser una URL válida",
+	}),
+	"min": LocalizedValidationErrorMessageFunc(map[string]string{
+		"en": "Param should be greater than {param}",
+		"ru": "Параметр должен быть больше {param}",
+		"es": "El parámetro debe ser mayor que {param}",
+	}),
+	"max": LocalizedValidationErrorMessageFunc(map[string]string{
+		"en": "Param should be less than {param}",
+		"ru": "Параметр должен быть меньше {param}",
+		"es": "El parámetro debe ser menor que {param}",
+	}),
+}
+
+var unknownValidationErrorMessage = LocalizedValidationErrorMessageFunc(map[string]string{
+	"en": "Unknown error",
+	"ru": "Неизвестная ошибка",
+	"es": "Error desconocido",
+})
+
+// LocalizedValidationErrorMessageFunc creates a ValidationErrorMessageFunc choosing the message for the response
+// language from messages keyed by base language (e.g. "en", "ru"), falling back to English. In the messages
+// `{param}` is replaced with the tag parameter and `{values}` with the space-separated parameter joined by commas.
+//
+// **Example:**
+// ```go
+//
+//	casual.AddValidationErrorMessage("notblank", casual.LocalizedValidationErrorMessageFunc(map[string]string{
+//	    "en": "Should not be blank",
+//	    "es": "No debe estar en blanco",
+//	}))
+//
+// ```
+func LocalizedValidationErrorMessageFunc(messages map[string]string) ValidationErrorMessageFunc {
+	return func(lang *string, fe validator.FieldError) string {
+		message, ok := messages[baseLang(lang)]
+		if !ok {
+			message = messages[defaultValidationLang]
+		}
+
+		return strings.NewReplacer(
+			"{param}", fe.Param(),
+			"{values}", strings.Join(strings.Split(fe.Param(), " "), ","),
+		).Replace(message)
+	}
+}
+
+// baseLang returns the lowercased base language of a language tag, e.g. "es" for "es-MX".
+func baseLang(lang *string) string {
+	if lang == nil {
+		return defaultValidationLang
+	}
+
+	base, _, _ := strings.Cut(strings.ReplaceAll(*lang, "_", "-"), "-")
+
+	return strings.ToLower(base)
+}
+
+// translateValidationError returns the message registered for the failed tag on the validator instance
+// for the translator matching the response language, falling back to getValidationErrorText.
+func translateValidationError(params *httpResponseParams, fe validator.FieldError) string {
+	if trans := params.translator(); trans != nil {
+		if msg := fe.Translate(trans); msg != fe.Error() {
+			return msg
+		}
+	}
+
+	return getValidationErrorText(params.lang, fe)
 }
 
 func getValidationErrorText(lang *string, fe validator.FieldError) string {
@@ -45,7 +123,7 @@ func getValidationErrorText(lang *string, fe validator.FieldError) string {
 		return msg(lang, fe)
 	}
 
-	return "Unknown error"
+	return unknownValidationErrorMessage(lang, fe)
 }
 
 type ValidationErrorMessageFunc func(lang *string, fe validator.FieldError) string
@@ -0,0 +1,255 @@
+package httpbara_test
+
+import (
+	"context"
+	"errors"
+	"github.com/gopybara/httpbara"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type threeValuesHandlerDescriber struct {
+	Get httpbara.Route `route:"GET /values"`
+}
+
+type threeValuesHandler struct {
+	threeValuesHandlerDescriber
+}
+
+func (h *threeValuesHandler) Get(ctx context.Context) (string, int, error) {
+	return "", 0, nil
+}
+
+type fourValuesHandlerDescriber struct {
+	Get httpbara.Route `route:"GET /values"`
+}
+
+type fourValuesHandler struct {
+	fourValuesHandlerDescriber
+}
+
+func (h *fourValuesHandler) Get(ctx context.Context) (string, http.Header, int, error) {
+	return "", nil, 0, nil
+}
+
+type noErrorHandlerDescriber struct {
+	Get httpbara.Route `route:"GET /values"`
+}
+
+type noErrorHandler struct {
+	noErrorHandlerDescriber
+}
+
+func (h *noErrorHandler) Get(ctx context.Context) string {
+	return ""
+}
+
+func TestAsHandlerInvalidCasualResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		describer any
+	}{
+		{name: "three values without header", describer: &threeValuesHandler{}},
+		{name: "four values", describer: &fourValuesHandler{}},
+		{name: "no error", describer: &noErrorHandler{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := httpbara.AsHandler(tt.describer)
+			if !errors.Is(err, httpbara.ErrInvalidCasualResponse) {
+				t.Fatalf("err = %v, want %v", err, httpbara.ErrInvalidCasualResponse)
+			}
+		})
+	}
+}
+
+type stringRequestHandlerDescriber struct {
+	Get httpbara.Route `route:"GET /values"`
+}
+
+type stringRequestHandler struct {
+	stringRequestHandlerDescriber
+}
+
+func (h *stringRequestHandler) Get(ctx context.Context, s string) error {
+	return nil
+}
+
+type mapRequestHandlerDescriber struct {
+	Get httpbara.Route `route:"GET /values"`
+}
+
+type mapRequestHandler struct {
+	mapRequestHandlerDescriber
+}
+
+func (h *mapRequestHandler) Get(ctx context.Context, req *map[string]string) error {
+	return nil
+}
+
+type valueRequest struct {
+	Name string `form:"name"`
+}
+
+type structRequestHandlerDescriber struct {
+	Value   httpbara.Route `route:"GET /value"`
+	Pointer httpbara.Route `route:"GET /pointer"`
+}
+
+type structRequestHandler struct {
+	structRequestHandlerDescriber
+}
+
+func (h *structRequestHandler) Value(ctx context.Context, req valueRequest) (string, error) {
+	return req.Name, nil
+}
+
+func (h *structRequestHandler) Pointer(ctx context.Context, req *valueRequest) (string, error) {
+	return req.Name, nil
+}
+
+func TestAsHandlerCasualRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		describer any
+		wantErr   error
+	}{
+		{name: "string", describer: &stringRequestHandler{}, wantErr: httpbara.ErrInvalidCasualRequest},
+		{name: "pointer to map", describer: &mapRequestHandler{}, wantErr: httpbara.ErrInvalidCasualRequest},
+		{name: "struct and pointer to struct", describer: &structRequestHandler{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := httpbara.AsHandler(tt.describer)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &structRequestHandler{})})
+	for _, path := range []string{"/value?name=bara", "/pointer?name=bara"} {
+		rec := serve(h, http.MethodGet, path, nil, nil)
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"bara"`) {
+			t.Fatalf("GET %s = %d %q, want the bound name", path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+type arityHandlerDescriber struct {
+	ErrorOnly  httpbara.Route `route:"GET /error-only"`
+	WithData   httpbara.Route `route:"GET /data"`
+	WithHeader httpbara.Route `route:"GET /header"`
+}
+
+type arityHandler struct {
+	arityHandlerDescriber
+}
+
+func (h *arityHandler) ErrorOnly(ctx context.Context) error {
+	return nil
+}
+
+func (h *arityHandler) WithData(ctx context.Context) (string, error) {
+	return "data", nil
+}
+
+func (h *arityHandler) WithHeader(ctx context.Context) (string, http.Header, error) {
+	return "header", http.Header{"X-Total-Count": {"1"}}, nil
+}
+
+func TestAsHandlerCasualResponseArity(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		status   int
+		wantBody string
+		header   string
+	}{
+		{name: "error", target: "/error-only", status: http.StatusNoContent},
+		{name: "data and error", target: "/data", status: http.StatusOK, wantBody: `"data"`},
+		{name: "data, header and error", target: "/header", status: http.StatusOK, wantBody: `"header"`, header: "1"},
+	}
+
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &arityHandler{})})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, tt.target, nil, nil)
+			if rec.Code != tt.status || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Fatalf("GET %s = %d %q, want %d with %q", tt.target, rec.Code, rec.Body.String(), tt.status, tt.wantBody)
+			}
+
+			if got := rec.Header().Get("X-Total-Count"); got != tt.header {
+				t.Fatalf("X-Total-Count = %q, want %q", got, tt.header)
+			}
+		})
+	}
+}
+
+type resultItem struct {
+	Name string `json:"name"`
+}
+
+type resultHandlerDescriber struct {
+	Get httpbara.Route `route:"GET /result"`
+}
+
+// resultHandler returns the data and error set by the test.
+type resultHandler struct {
+	resultHandlerDescriber
+
+	data *resultItem
+	err  error
+}
+
+func (h *resultHandler) Get(ctx context.Context) (*resultItem, error) {
+	return h.data, h.err
+}
+
+func TestCasualDataAndError(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     *resultItem
+		err      error
+		status   int
+		wantBody string
+		notBody  string
+	}{
+		{name: "data", data: &resultItem{Name: "bara"}, status: http.StatusOK, wantBody: `"name":"bara"`},
+		{name: "error", err: casual.ErrNotFound, status: http.StatusNotFound, wantBody: `"message":"not found"`},
+		{
+			name:     "data and error",
+			data:     &resultItem{Name: "bara"},
+			err:      casual.NewHTTPErrorFromMessage(http.StatusConflict, "conflict"),
+			status:   http.StatusConflict,
+			wantBody: `"message":"conflict"`,
+			notBody:  "bara",
+		},
+		{name: "plain error", err: errors.New("boom"), status: http.StatusInternalServerError, wantBody: `"status":500`},
+		{name: "neither", status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &resultHandler{data: tt.data, err: tt.err})})
+
+			rec := serve(h, http.MethodGet, "/result", nil, map[string]string{"Accept": "application/json"})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Fatalf("body = %q, want it to contain %q", rec.Body.String(), tt.wantBody)
+			}
+
+			if tt.notBody != "" && strings.Contains(rec.Body.String(), tt.notBody) {
+				t.Fatalf("body = %q, want the data left out", rec.Body.String())
+			}
+		})
+	}
+}
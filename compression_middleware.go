@@ -0,0 +1,344 @@
+package httpbara
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultCompressionMinSize = 1 << 10
+
+// defaultCompressibleTypes are the content types compressed by default. Already compressed formats,
+// such as images (other than SVG), archives or videos, are left out on purpose.
+var defaultCompressibleTypes = []string{
+	"text/html",
+	"text/plain",
+	"text/css",
+	"text/csv",
+	"text/xml",
+	"text/javascript",
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"application/xml",
+	"application/yaml",
+	"application/x-yaml",
+	"image/svg+xml",
+}
+
+// compressionEncoder is a content coding the compression middleware can respond with.
+type compressionEncoder struct {
+	encoding  string
+	newWriter func(w io.Writer) (io.WriteCloser, error)
+}
+
+type compressionOpts struct {
+	level        int
+	brotliLevel  int
+	minSize      int
+	contentTypes []string
+	encoders     []compressionEncoder
+}
+
+type CompressionOpt func(*compressionOpts)
+
+// WithCompressionLevel sets the gzip compression level, from gzip.BestSpeed to gzip.BestCompression
+// (gzip.DefaultCompression by default).
+func WithCompressionLevel(level int) CompressionOpt {
+	return func(opts *compressionOpts) {
+		opts.level = level
+	}
+}
+
+// WithCompressionBrotliLevel sets the brotli compression level, from brotli.BestSpeed (0) to brotli.BestCompression
+// (11), brotli.DefaultCompression (6) by default.
+func WithCompressionBrotliLevel(level int) CompressionOpt {
+	return func(opts *compressionOpts) {
+		opts.brotliLevel = level
+	}
+}
+
+// WithCompressionMinSize sets the size in bytes a response body must reach to be compressed (1 KiB by default),
+// as compressing smaller bodies costs more than it saves.
+func WithCompressionMinSize(size int) CompressionOpt {
+	return func(opts *compressionOpts) {
+		opts.minSize = size
+	}
+}
+
+// WithCompressionContentTypes replaces the content types that are compressed, e.g. "application/json" or
+// "text/*" for every text type. By default text, JSON, XML, YAML, JavaScript and SVG responses are compressed.
+func WithCompressionContentTypes(types ...string) CompressionOpt {
+	return func(opts *compressionOpts) {
+		opts.contentTypes = types
+	}
+}
+
+// WithCompressionEncoder registers a content coding next to brotli and gzip, preferred over them when the client
+// accepts several with the same q-value, e.g. zstd. Registering "br" or "gzip" replaces the built-in encoder:
+//
+//	httpbara.WithCompressionEncoder("zstd", func(w io.Writer) (io.WriteCloser, error) {
+//	    return zstd.NewWriter(w)
+//	})
+//
+// The writer is flushed when the handler flushes the response if it has a `Flush() error` method,
+// and closed once the request is handled.
+func WithCompressionEncoder(encoding string, newWriter func(w io.Writer) (io.WriteCloser, error)) CompressionOpt {
+	return func(opts *compressionOpts) {
+		opts.encoders = append([]compressionEncoder{{encoding: strings.ToLower(encoding), newWriter: newWriter}}, opts.encoders...)
+	}
+}
+
+type compressionMiddlewareDescriber struct {
+	Middleware Middleware `middleware:"gzip"`
+}
+
+type compressionMiddleware struct {
+	compressionMiddlewareDescriber
+
+	opts compressionOpts
+}
+
+// NewCompressionMiddleware creates a middleware named "gzip" that compresses response bodies with the content
+// coding negotiated from the `Accept-Encoding` header: brotli ("br"), gzip, or the ones registered via
+// WithCompressionEncoder. Brotli is preferred over gzip when the client accepts both with the same q-value.
+// Only bodies of an allowed content type reaching the minimum size are compressed, and responses that already
+// carry a `Content-Encoding` are left untouched. Compressible responses get a `Vary: Accept-Encoding` header,
+// so caches keep the variants apart.
+//
+// The body is buffered until it reaches the minimum size, and streamed from there. Flushing the response
+// flushes the compressor, so server-sent events keep working when their content type is allowed.
+//
+// **Example:**
+// ```go
+//
+//	compression, _ := httpbara.NewCompressionMiddleware(
+//	    httpbara.WithCompressionMinSize(512),
+//	    httpbara.WithCompressionContentTypes("application/json", "text/*"),
+//	)
+//	engine, _ := httpbara.New(handlers, httpbara.WithRootMiddlewares(compression))
+//
+// ```
+func NewCompressionMiddleware(opts ...CompressionOpt) (*Handler, error) {
+	cm := compressionMiddleware{
+		opts: compressionOpts{
+			level:        gzip.DefaultCompression,
+			brotliLevel:  brotli.DefaultCompression,
+			minSize:      defaultCompressionMinSize,
+			contentTypes: defaultCompressibleTypes,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&cm.opts)
+	}
+
+	if _, err := gzip.NewWriterLevel(io.Discard, cm.opts.level); err != nil {
+		return nil, fmt.Errorf("invalid compression level: %w", err)
+	}
+
+	if cm.opts.brotliLevel < brotli.BestSpeed || cm.opts.brotliLevel > brotli.BestCompression {
+		return nil, fmt.Errorf("invalid brotli compression level: %d", cm.opts.brotliLevel)
+	}
+
+	level, brotliLevel := cm.opts.level, cm.opts.brotliLevel
+	cm.opts.encoders = append(cm.opts.encoders,
+		compressionEncoder{
+			encoding: "br",
+			newWriter: func(w io.Writer) (io.WriteCloser, error) {
+				return brotli.NewWriterLevel(w, brotliLevel), nil
+			},
+		},
+		compressionEncoder{
+			encoding: "gzip",
+			newWriter: func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriterLevel(w, level)
+			},
+		},
+	)
+
+	return AsHandler(&cm)
+}
+
+func (cm *compressionMiddleware) Middleware(ctx *gin.Context) {
+	if ctx.Request.Method == http.MethodHead {
+		ctx.Next()
+		return
+	}
+
+	writer := &compressionWriter{
+		ResponseWriter: ctx.Writer,
+		cm:             cm,
+		encoder:        cm.negotiate(ctx.GetHeader("Accept-Encoding")),
+	}
+	ctx.Writer = writer
+
+	defer func() {
+		writer.close()
+		ctx.Writer = writer.ResponseWriter
+	}()
+
+	ctx.Next()
+}
+
+// negotiate returns the encoder with the highest q-value in an Accept-Encoding header, preferring the registered
+// encoders, then brotli, over gzip for equal q-values, or nil when the client accepts none of them.
+func (cm *compressionMiddleware) negotiate(header string) *compressionEncoder {
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		accepted[name] = q
+	}
+
+	var best *compressionEncoder
+	bestQ := 0.0
+	for i, encoder := range cm.opts.encoders {
+		q, ok := accepted[encoder.encoding]
+		if !ok {
+			q = accepted["*"]
+		}
+
+		if q > bestQ {
+			best, bestQ = &cm.opts.encoders[i], q
+		}
+	}
+
+	return best
+}
+
+// compressible reports whether responses of the given content type are compressed.
+func (cm *compressionMiddleware) compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	for _, allowed := range cm.opts.contentTypes {
+		allowed = strings.ToLower(allowed)
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compressionWriter buffers the response body until it is known whether it is compressed, then writes it
+// through the encoder or as is.
+type compressionWriter struct {
+	gin.ResponseWriter
+
+	cm      *compressionMiddleware
+	encoder *compressionEncoder
+
+	buf     []byte
+	decided bool
+	enc     io.WriteCloser
+}
+
+// decide chooses whether the response is compressed once the minimum size is reached, the headers are flushed
+// or the handler is done, and writes the buffered body accordingly.
+func (w *compressionWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	header := w.Header()
+	status := w.Status()
+	bodyAllowed := status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+
+	if bodyAllowed && header.Get("Content-Encoding") == "" && len(w.buf) > 0 {
+		// The type must be known before the body is compressed, as it can no longer be sniffed afterwards
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", http.DetectContentType(w.buf))
+		}
+
+		if w.cm.compressible(header.Get("Content-Type")) {
+			header.Add("Vary", "Accept-Encoding")
+
+			if w.encoder != nil && len(w.buf) >= w.cm.opts.minSize {
+				enc, err := w.encoder.newWriter(w.ResponseWriter)
+				if err == nil {
+					header.Set("Content-Encoding", w.encoder.encoding)
+					header.Del("Content-Length")
+					w.enc = enc
+				}
+			}
+		}
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) > 0 {
+		_, _ = w.write(buf)
+	}
+}
+
+func (w *compressionWriter) write(data []byte) (int, error) {
+	if w.enc != nil {
+		return w.enc.Write(data)
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressionWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= w.cm.opts.minSize {
+		w.decide()
+	}
+
+	return len(data), nil
+}
+
+func (w *compressionWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressionWriter) WriteHeaderNow() {
+	w.decide()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressionWriter) Flush() {
+	w.decide()
+
+	if flusher, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+
+	w.ResponseWriter.Flush()
+}
+
+// close writes what is still buffered and finishes the compressed stream.
+func (w *compressionWriter) close() {
+	w.decide()
+
+	if w.enc != nil {
+		_ = w.enc.Close()
+	}
+}
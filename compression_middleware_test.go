@@ -0,0 +1,111 @@
+package httpbara_test
+
+import (
+	"compress/gzip"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var compressionBody = strings.Repeat("compressible text ", 128)
+
+type compressionHandlerDescriber struct {
+	Text  httpbara.Route `route:"GET /text" middlewares:"gzip"`
+	Small httpbara.Route `route:"GET /small" middlewares:"gzip"`
+}
+
+type compressionHandler struct {
+	compressionHandlerDescriber
+}
+
+func (h *compressionHandler) Text(ctx *gin.Context) {
+	ctx.String(http.StatusOK, compressionBody)
+}
+
+func (h *compressionHandler) Small(ctx *gin.Context) {
+	ctx.String(http.StatusOK, "tiny")
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		encoding       string
+		body           string
+	}{
+		{name: "brotli", path: "/text", acceptEncoding: "br", encoding: "br", body: compressionBody},
+		{name: "gzip", path: "/text", acceptEncoding: "gzip", encoding: "gzip", body: compressionBody},
+		{name: "brotli preferred", path: "/text", acceptEncoding: "gzip, br", encoding: "br", body: compressionBody},
+		{name: "higher q-value wins", path: "/text", acceptEncoding: "br;q=0.5, gzip", encoding: "gzip", body: compressionBody},
+		{name: "wildcard", path: "/text", acceptEncoding: "*", encoding: "br", body: compressionBody},
+		{name: "identity only", path: "/text", acceptEncoding: "identity", encoding: "", body: compressionBody},
+		{name: "refused coding", path: "/text", acceptEncoding: "br;q=0", encoding: "", body: compressionBody},
+		{name: "below min size", path: "/small", acceptEncoding: "br, gzip", encoding: "", body: "tiny"},
+	}
+
+	compression, err := httpbara.NewCompressionMiddleware()
+	if err != nil {
+		t.Fatalf("failed to create compression middleware: %v", err)
+	}
+
+	h := newTestEngine(t, []*httpbara.Handler{compression, mustHandler(t, &compressionHandler{})})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, tt.path, nil, map[string]string{"Accept-Encoding": tt.acceptEncoding})
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+
+			if encoding := rec.Header().Get("Content-Encoding"); encoding != tt.encoding {
+				t.Fatalf("Content-Encoding = %q, want %q", encoding, tt.encoding)
+			}
+
+			if vary := rec.Header().Get("Vary"); vary != "Accept-Encoding" {
+				t.Fatalf("Vary = %q, want Accept-Encoding", vary)
+			}
+
+			var body io.Reader = rec.Body
+			switch tt.encoding {
+			case "br":
+				body = brotli.NewReader(body)
+			case "gzip":
+				if body, err = gzip.NewReader(body); err != nil {
+					t.Fatalf("failed to read gzip body: %v", err)
+				}
+			}
+
+			decoded, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+
+			if string(decoded) != tt.body {
+				t.Fatalf("body = %q, want %q", decoded, tt.body)
+			}
+		})
+	}
+}
+
+func TestNewCompressionMiddlewareInvalidLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  httpbara.CompressionOpt
+	}{
+		{name: "gzip", opt: httpbara.WithCompressionLevel(42)},
+		{name: "brotli", opt: httpbara.WithCompressionBrotliLevel(12)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := httpbara.NewCompressionMiddleware(tt.opt); err == nil {
+				t.Fatal("expected an error for an invalid level")
+			}
+		})
+	}
+}
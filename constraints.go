@@ -0,0 +1,164 @@
+package httpbara
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidConstraint is returned when a `constraints` tag cannot be parsed.
+	ErrInvalidConstraint = errors.New("invalid constraint")
+
+	// ErrUnknownConstraintParam is returned when a `constraints` tag names a parameter the route path does not declare.
+	ErrUnknownConstraintParam = errors.New("constraint on undeclared path parameter")
+
+	uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// paramConstraint describes a format restriction applied to a single path parameter.
+//
+// Fields:
+// - `param`: The name of the path parameter (e.g., "id" for `/users/:id`).
+// - `kind`: The constraint kind as written in the tag ("int", "uuid" or "regex").
+// - `check`: The function that reports whether a parameter value satisfies the constraint.
+type paramConstraint struct {
+	param string
+	kind  string
+	check func(value string) bool
+}
+
+// parseConstraintsTag parses a `constraints` tag which holds a comma-separated list of
+// `param=kind` pairs. Supported kinds are `int`, `uuid` and `regex(<pattern>)`.
+// Commas inside the regex parentheses are not treated as separators.
+//
+// **Example:**
+// ```go
+// GetUser Route `route:"GET /users/:id/posts/:slug" constraints:"id=uuid,slug=regex(^[a-z0-9-]{1,64}$)"`
+// ```
+func (h *Handler) parseConstraintsTag(tag string) ([]*paramConstraint, error) {
+	result := make([]*paramConstraint, 0)
+
+	for _, value := range splitConstraints(tag) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		param, kind, ok := strings.Cut(value, "=")
+		param = strings.TrimSpace(param)
+		kind = strings.TrimSpace(kind)
+		if !ok || param == "" || kind == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidConstraint, value)
+		}
+
+		constraint := &paramConstraint{
+			param: param,
+			kind:  kind,
+		}
+
+		switch {
+		case kind == "int":
+			constraint.check = func(value string) bool {
+				_, err := strconv.ParseInt(value, 10, 64)
+				return err == nil
+			}
+		case kind == "uuid":
+			constraint.check = uuidRegexp.MatchString
+		case strings.HasPrefix(kind, "regex(") && strings.HasSuffix(kind, ")"):
+			re, err := regexp.Compile(strings.TrimSuffix(strings.TrimPrefix(kind, "regex("), ")"))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q: %w", ErrInvalidConstraint, value, err)
+			}
+
+			constraint.kind = "regex"
+			constraint.check = re.MatchString
+		default:
+			return nil, fmt.Errorf("%w: unknown kind %q for param %q", ErrInvalidConstraint, kind, param)
+		}
+
+		result = append(result, constraint)
+	}
+
+	return result, nil
+}
+
+// checkConstraintParams returns ErrUnknownConstraintParam when a constraint names a parameter that path does not
+// declare as `:name` or `*name`, as every request would then fail the constraint.
+func checkConstraintParams(constraints []*paramConstraint, path string) error {
+	declared := make(map[string]struct{})
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			declared[name] = struct{}{}
+		} else if name, ok := strings.CutPrefix(segment, "*"); ok {
+			declared[name] = struct{}{}
+		}
+	}
+
+	for _, constraint := range constraints {
+		if _, ok := declared[constraint.param]; !ok {
+			return fmt.Errorf("%w: %q is not declared by %s", ErrUnknownConstraintParam, constraint.param, path)
+		}
+	}
+
+	return nil
+}
+
+// splitConstraints splits a constraints tag by commas that are not enclosed in parentheses.
+func splitConstraints(tag string) []string {
+	parts := make([]string, 0)
+	depth := 0
+	start := 0
+
+	for i, char := range tag {
+		switch char {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, tag[start:])
+}
+
+// constraintsMiddleware builds a Gin handler that validates path parameters against the given constraints.
+// Every violated constraint is reported as a field error in a 400 envelope rendered by the casual error responder.
+func (c *core) constraintsMiddleware(constraints []*paramConstraint) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var details []*casual.HttpErrorField
+
+		for _, constraint := range constraints {
+			if !constraint.check(ctx.Param(constraint.param)) {
+				details = append(details, &casual.HttpErrorField{
+					Field: constraint.param,
+					Issue: "Param should be valid " + constraint.kind,
+				})
+			}
+		}
+
+		if len(details) > 0 {
+			rcb := c.getResponseCallback(ctx)
+			rcb(c.casualResponseErrorHandler(
+				casual.NewHTTPErrorWithDetails(http.StatusBadRequest, "invalid path parameters", details...),
+				languageParams(ctx)...,
+			))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
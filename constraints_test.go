@@ -0,0 +1,85 @@
+package httpbara_test
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"testing"
+)
+
+type constraintsHandlerDescriber struct {
+	Tenants httpbara.Group `group:"/tenants/:tenant"`
+
+	User       httpbara.Route `route:"GET /users/:id" constraints:"id=int"`
+	TenantUser httpbara.Route `route:"GET /users/:id" group:"tenants" constraints:"tenant=uuid,id=int"`
+}
+
+type constraintsHandler struct {
+	constraintsHandlerDescriber
+}
+
+func (h *constraintsHandler) User(ctx *gin.Context) {
+	ctx.Status(http.StatusOK)
+}
+
+func (h *constraintsHandler) TenantUser(ctx *gin.Context) {
+	ctx.Status(http.StatusOK)
+}
+
+func TestConstraints(t *testing.T) {
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &constraintsHandler{})})
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "valid int", path: "/users/42", status: http.StatusOK},
+		{name: "invalid int", path: "/users/abc", status: http.StatusBadRequest},
+		{name: "valid group param", path: "/tenants/4d7a3c7e-8f7b-4f7e-9d38-2b8c0e1f6a90/users/42", status: http.StatusOK},
+		{name: "invalid group param", path: "/tenants/acme/users/42", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, tt.path, nil, nil)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
+
+type undeclaredParamHandlerDescriber struct {
+	User httpbara.Route `route:"GET /users/:id" constraints:"userId=int"`
+}
+
+type undeclaredParamHandler struct {
+	undeclaredParamHandlerDescriber
+}
+
+func (h *undeclaredParamHandler) User(ctx *gin.Context) {}
+
+type undeclaredGroupParamHandlerDescriber struct {
+	Tenants httpbara.Group `group:"/tenants/:tenant"`
+
+	User httpbara.Route `route:"GET /users/:id" group:"tenants" constraints:"org=uuid"`
+}
+
+type undeclaredGroupParamHandler struct {
+	undeclaredGroupParamHandlerDescriber
+}
+
+func (h *undeclaredGroupParamHandler) User(ctx *gin.Context) {}
+
+func TestConstraintsUndeclaredParam(t *testing.T) {
+	if _, err := httpbara.AsHandler(&undeclaredParamHandler{}); !errors.Is(err, httpbara.ErrUnknownConstraintParam) {
+		t.Fatalf("AsHandler err = %v, want %v", err, httpbara.ErrUnknownConstraintParam)
+	}
+
+	handler := mustHandler(t, &undeclaredGroupParamHandler{})
+	if _, err := httpbara.New([]*httpbara.Handler{handler}, httpbara.WithLogger(discardLogger{})); !errors.Is(err, httpbara.ErrUnknownConstraintParam) {
+		t.Fatalf("New err = %v, want %v", err, httpbara.ErrUnknownConstraintParam)
+	}
+}
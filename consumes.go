@@ -0,0 +1,62 @@
+package httpbara
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedMediaType is rendered through the casual error responder with 415 when the content type
+	// of a request does not match the `consumes` tag of its route.
+	ErrUnsupportedMediaType = casual.NewHTTPErrorFromMessage(http.StatusUnsupportedMediaType, "unsupported media type")
+
+	// ErrUnsupportedConsumesTag is returned by AsHandler when a `consumes` tag is set on a websocket or static route,
+	// which receive no request body.
+	ErrUnsupportedConsumesTag = errors.New("consumes tag on a route without request body")
+)
+
+// parseConsumesTag splits a comma-separated list of media types, trimming spaces and lowercasing them.
+// Parameters such as `charset` are not part of the match and are dropped.
+func (h *Handler) parseConsumesTag(tag string) []string {
+	var consumes []string
+
+	for _, mediaType := range strings.Split(tag, ",") {
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if mediaType != "" {
+			consumes = append(consumes, mediaType)
+		}
+	}
+
+	return consumes
+}
+
+// consumesMiddleware builds a Gin handler answering requests whose content type is not one of consumes
+// with ErrUnsupportedMediaType, before their body is bound. A media type ending in `/*` (e.g. "image/*")
+// matches every subtype. Requests without body and content type are let through.
+func (c *core) consumesMiddleware(consumes []string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		contentType := strings.ToLower(ctx.ContentType())
+		if contentType == "" && ctx.Request.ContentLength == 0 {
+			ctx.Next()
+			return
+		}
+
+		for _, mediaType := range consumes {
+			if mediaType == contentType ||
+				strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(mediaType, "*")) {
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.Header("Accept", strings.Join(consumes, ", "))
+
+		rcb := c.getResponseCallback(ctx)
+		rcb(c.casualResponseErrorHandler(ErrUnsupportedMediaType, languageParams(ctx)...))
+		ctx.Abort()
+	}
+}
@@ -0,0 +1,76 @@
+package httpbara
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// readerResponse returns the io.Reader returned by a casual handler, e.g. `(io.Reader, error)` or
+// `(*os.File, error)`, or false when the value is not a non-nil reader.
+func readerResponse(value reflect.Value) (io.Reader, bool) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil, false
+		}
+	}
+
+	reader, ok := value.Interface().(io.Reader)
+
+	return reader, ok
+}
+
+// serveReader writes a reader returned by a casual handler as the response body instead of an envelope,
+// closing it afterwards when it is an io.Closer.
+//
+// Readers that are also io.ReadSeeker (e.g. *os.File) are served with http.ServeContent, which answers
+// `Range` requests with 206 Partial Content, honors `If-Range` and the conditional request headers, and
+// detects the content type from the file name or the content. The file name is taken from a `Name() string`
+// method and the modification time from a `Stat() (os.FileInfo, error)` or `ModTime() time.Time` method.
+// Other readers are streamed with statusCode as they are read, as `application/octet-stream` unless the handler
+// returned a `Content-Type` header.
+func serveReader(ctx *gin.Context, statusCode int, reader io.Reader) {
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		http.ServeContent(ctx.Writer, ctx.Request, readerName(reader), readerModTime(reader), seeker)
+		return
+	}
+
+	if ctx.Writer.Header().Get("Content-Type") == "" {
+		ctx.Header("Content-Type", "application/octet-stream")
+	}
+
+	ctx.Status(statusCode)
+	_, _ = io.Copy(ctx.Writer, reader)
+}
+
+// readerName returns the base name of a reader with a `Name() string` method, such as *os.File.
+func readerName(reader io.Reader) string {
+	if named, ok := reader.(interface{ Name() string }); ok {
+		return filepath.Base(named.Name())
+	}
+
+	return ""
+}
+
+// readerModTime returns the modification time of a reader, or the zero time when it is unknown.
+func readerModTime(reader io.Reader) time.Time {
+	switch r := reader.(type) {
+	case interface{ Stat() (os.FileInfo, error) }:
+		if info, err := r.Stat(); err == nil {
+			return info.ModTime()
+		}
+	case interface{ ModTime() time.Time }:
+		return r.ModTime()
+	}
+
+	return time.Time{}
+}
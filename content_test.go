@@ -0,0 +1,148 @@
+package httpbara_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/gopybara/httpbara"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const contentBody = "0123456789"
+
+type contentHandlerDescriber struct {
+	Seeker httpbara.Route `route:"GET /seeker"`
+	File   httpbara.Route `route:"GET /file"`
+	Stream httpbara.Route `route:"GET /stream"`
+}
+
+type contentHandler struct {
+	contentHandlerDescriber
+
+	path string
+}
+
+func (h *contentHandler) Seeker(ctx context.Context) (io.Reader, error) {
+	return bytes.NewReader([]byte(contentBody)), nil
+}
+
+func (h *contentHandler) File(ctx context.Context) (*os.File, error) {
+	return os.Open(h.path)
+}
+
+func (h *contentHandler) Stream(ctx context.Context) (io.Reader, error) {
+	return io.MultiReader(strings.NewReader(contentBody)), nil
+}
+
+func TestReaderRangeRequests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "digits.txt")
+	if err := os.WriteFile(path, []byte(contentBody), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to set file time: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		target       string
+		headers      map[string]string
+		status       int
+		wantBody     string
+		contentRange string
+		contentType  string
+	}{
+		{name: "full content", target: "/seeker", status: http.StatusOK, wantBody: contentBody},
+		{
+			name:         "range",
+			target:       "/seeker",
+			headers:      map[string]string{"Range": "bytes=2-5"},
+			status:       http.StatusPartialContent,
+			wantBody:     "2345",
+			contentRange: "bytes 2-5/10",
+		},
+		{
+			name:         "suffix range",
+			target:       "/seeker",
+			headers:      map[string]string{"Range": "bytes=-3"},
+			status:       http.StatusPartialContent,
+			wantBody:     "789",
+			contentRange: "bytes 7-9/10",
+		},
+		{
+			name:         "unsatisfiable range",
+			target:       "/seeker",
+			headers:      map[string]string{"Range": "bytes=20-30"},
+			status:       http.StatusRequestedRangeNotSatisfiable,
+			contentRange: "bytes */10",
+		},
+		{
+			name:         "file range",
+			target:       "/file",
+			headers:      map[string]string{"Range": "bytes=0-1"},
+			status:       http.StatusPartialContent,
+			wantBody:     "01",
+			contentRange: "bytes 0-1/10",
+		},
+		{
+			name:        "file type from name",
+			target:      "/file",
+			status:      http.StatusOK,
+			wantBody:    contentBody,
+			contentType: "text/plain",
+		},
+		{
+			name:         "matching If-Range",
+			target:       "/file",
+			headers:      map[string]string{"Range": "bytes=0-1", "If-Range": modTime.Format(http.TimeFormat)},
+			status:       http.StatusPartialContent,
+			wantBody:     "01",
+			contentRange: "bytes 0-1/10",
+		},
+		{
+			name:     "outdated If-Range",
+			target:   "/file",
+			headers:  map[string]string{"Range": "bytes=0-1", "If-Range": modTime.Add(-time.Hour).Format(http.TimeFormat)},
+			status:   http.StatusOK,
+			wantBody: contentBody,
+		},
+		{
+			name:        "range ignored by streams",
+			target:      "/stream",
+			headers:     map[string]string{"Range": "bytes=2-5"},
+			status:      http.StatusOK,
+			wantBody:    contentBody,
+			contentType: "application/octet-stream",
+		},
+	}
+
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &contentHandler{path: path})})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, tt.target, nil, tt.headers)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Fatalf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+
+			if got := rec.Header().Get("Content-Range"); got != tt.contentRange {
+				t.Fatalf("Content-Range = %q, want %q", got, tt.contentRange)
+			}
+
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+				t.Fatalf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package httpbara
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CorsOptions configures the middleware created by NewCorsMiddleware.
+//
+// Fields:
+// - `AllowedOrigins`: Allowed origins. "*" allows any origin, a "*" inside an origin matches any subdomain part (e.g. "https://*.example.com").
+// - `AllowedOriginPatterns`: Regular expressions an origin may match in addition to `AllowedOrigins`.
+// - `AllowedMethods`: Methods allowed in preflight requests. Defaults to GET, HEAD, POST, PUT, PATCH and DELETE.
+// - `AllowedHeaders`: Request headers allowed in preflight requests. When empty the requested headers are allowed.
+// - `ExposedHeaders`: Response headers the browser may expose to scripts.
+// - `AllowCredentials`: Whether cookies and authorization headers may be sent. The matched origin is echoed instead of "*".
+// - `MaxAge`: How long the browser may cache preflight results. Zero omits the header.
+type CorsOptions struct {
+	AllowedOrigins        []string
+	AllowedOriginPatterns []*regexp.Regexp
+	AllowedMethods        []string
+	AllowedHeaders        []string
+	ExposedHeaders        []string
+	AllowCredentials      bool
+	MaxAge                time.Duration
+}
+
+type corsMiddlewareDescriber struct {
+	Middleware Middleware `middleware:"cors"`
+	Preflight  Route      `route:"OPTIONS /*path" middlewares:"cors"`
+}
+
+type corsMiddleware struct {
+	corsMiddlewareDescriber
+
+	opts      CorsOptions
+	anyOrigin bool
+	origins   map[string]struct{}
+	patterns  []*regexp.Regexp
+}
+
+// NewCorsMiddleware creates a middleware named "cors" that sets the CORS response headers for allowed origins.
+// Reference it with `middlewares:"cors"` on routes or groups, or pass it to WithRootMiddlewares.
+//
+// When the handler is passed to New, it also registers a catch-all `OPTIONS /*path` route that answers
+// preflight requests with 204 and the preflight headers, so no OPTIONS routes have to be declared.
+// Because of that catch-all route the engine must not declare other OPTIONS routes.
+//
+// **Example:**
+// ```go
+//
+//	cors, _ := httpbara.NewCorsMiddleware(httpbara.CorsOptions{
+//	    AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+//	    AllowCredentials: true,
+//	    MaxAge:           time.Hour,
+//	})
+//	engine, _ := httpbara.New(append(handlers, cors), httpbara.WithRootMiddlewares(cors))
+//
+// ```
+func NewCorsMiddleware(opts CorsOptions) (*Handler, error) {
+	cm := corsMiddleware{
+		opts:     opts,
+		origins:  make(map[string]struct{}),
+		patterns: append([]*regexp.Regexp{}, opts.AllowedOriginPatterns...),
+	}
+
+	if len(cm.opts.AllowedMethods) == 0 {
+		cm.opts.AllowedMethods = []string{
+			http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+		}
+	}
+
+	for _, origin := range opts.AllowedOrigins {
+		switch {
+		case origin == "*":
+			cm.anyOrigin = true
+		case strings.Contains(origin, "*"):
+			pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(origin)), `\*`, `[^/]+`) + "$"
+			cm.patterns = append(cm.patterns, regexp.MustCompile(pattern))
+		default:
+			cm.origins[strings.ToLower(origin)] = struct{}{}
+		}
+	}
+
+	return AsHandler(&cm)
+}
+
+func (cm *corsMiddleware) Middleware(ctx *gin.Context) {
+	origin := ctx.GetHeader("Origin")
+	if origin == "" {
+		ctx.Next()
+		return
+	}
+
+	header := ctx.Writer.Header()
+	header.Add("Vary", "Origin")
+
+	if !cm.allowed(origin) {
+		if cm.isPreflight(ctx) {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		ctx.Next()
+		return
+	}
+
+	if cm.anyOrigin && !cm.opts.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if cm.opts.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !cm.isPreflight(ctx) {
+		if len(cm.opts.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(cm.opts.ExposedHeaders, ", "))
+		}
+
+		ctx.Next()
+		return
+	}
+
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+	header.Set("Access-Control-Allow-Methods", strings.Join(cm.opts.AllowedMethods, ", "))
+
+	if len(cm.opts.AllowedHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(cm.opts.AllowedHeaders, ", "))
+	} else if requested := ctx.GetHeader("Access-Control-Request-Headers"); requested != "" {
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+
+	if cm.opts.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(cm.opts.MaxAge.Seconds())))
+	}
+
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
+
+// Preflight answers OPTIONS requests that were not short-circuited by the middleware, i.e. requests
+// that are not CORS preflight requests.
+func (cm *corsMiddleware) Preflight(ctx *gin.Context) {
+	ctx.Status(http.StatusNoContent)
+}
+
+func (cm *corsMiddleware) isPreflight(ctx *gin.Context) bool {
+	return ctx.Request.Method == http.MethodOptions && ctx.GetHeader("Access-Control-Request-Method") != ""
+}
+
+func (cm *corsMiddleware) allowed(origin string) bool {
+	if cm.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if _, ok := cm.origins[origin]; ok {
+		return true
+	}
+
+	for _, pattern := range cm.patterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package httpbara
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RouteSpec describes a route declared by a Handler, for documentation generators such as the openapi package.
+//
+// Fields:
+// - `Name`: The name of the describer field declaring the route (e.g., "GetProduct").
+// - `Method`: The HTTP method (e.g., "GET") or "ANY".
+// - `Path`: The full path including the prefixes of the route's group and its parents (e.g., "/api/v3/products/:id").
+// - `Group`: The name of the group the route belongs to, empty if none.
+// - `Constraints`: The constraint kind ("int", "uuid" or "regex") per constrained path parameter.
+// - `Request`: The request type of a casual handler, nil for plain Gin handlers and casual handlers without request.
+// - `Response`: The data type returned by a casual handler, nil for plain Gin handlers and handlers returning only an error.
+type RouteSpec struct {
+	Name        string
+	Method      string
+	Path        string
+	Group       string
+	Constraints map[string]string
+	Request     reflect.Type
+	Response    reflect.Type
+}
+
+// DescribeRoutes returns a RouteSpec for every route declared by the given handlers, resolving group prefixes
+// across all handlers the same way New does. It does not register anything and can be called at build time.
+func DescribeRoutes(handlers []*Handler) ([]RouteSpec, error) {
+	c := &core{
+		flatGroups: make(map[string]*Group),
+	}
+	c.log = NewFmtLogger()
+
+	for _, handler := range handlers {
+		for _, group := range handler.groups {
+			c.flatGroups[group.name] = group
+		}
+	}
+
+	specs := make([]RouteSpec, 0)
+	for _, handler := range handlers {
+		for _, route := range handler.routes {
+			spec, err := c.describeRoute(route.name, route.method, route.path, route.group, route.constraints)
+			if err != nil {
+				return nil, err
+			}
+
+			specs = append(specs, spec)
+		}
+
+		for _, route := range handler.casualRoutes {
+			spec, err := c.describeRoute(route.name, route.method, route.path, route.group, route.constraints)
+			if err != nil {
+				return nil, err
+			}
+
+			methodType := route.handler.rm.Type
+			spec.Request = route.handler.requestType()
+			if methodType.NumOut() >= 2 {
+				spec.Response = methodType.Out(0)
+			}
+
+			specs = append(specs, spec)
+		}
+	}
+
+	return specs, nil
+}
+
+func (c *core) describeRoute(name, method, path, group string, constraints []*paramConstraint) (RouteSpec, error) {
+	spec := RouteSpec{
+		Name:        name,
+		Method:      method,
+		Path:        path,
+		Group:       group,
+		Constraints: make(map[string]string, len(constraints)),
+	}
+
+	if group != "" {
+		if _, ok := c.flatGroups[group]; ok {
+			chain, err := c.groupChain(group)
+			if err != nil {
+				return RouteSpec{}, fmt.Errorf("failed to resolve group of route %s %s: %w", method, path, err)
+			}
+
+			spec.Path = joinGroupPath(chain, path)
+		}
+	}
+
+	for _, constraint := range constraints {
+		spec.Constraints[constraint.param] = constraint.kind
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,102 @@
+package httpbara_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"testing"
+)
+
+type accountStatus struct {
+	Code int               `json:"-"`
+	Tags map[string]string `json:"-"`
+}
+
+// StatusCode and Meta dereference the value, as most implementations do.
+func (s *accountStatus) StatusCode() int {
+	return s.Code
+}
+
+func (s *accountStatus) Meta() map[string]string {
+	return s.Tags
+}
+
+type emptyResponseHandlerDescriber struct {
+	Ping   httpbara.Route `route:"GET /ping"`
+	Status httpbara.Route `route:"GET /status"`
+}
+
+type emptyResponseHandler struct {
+	emptyResponseHandlerDescriber
+}
+
+func (h *emptyResponseHandler) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (h *emptyResponseHandler) Status(ctx context.Context) (*accountStatus, error) {
+	return nil, nil
+}
+
+func TestEmptyResponseStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []httpbara.ParamsCb
+		path   string
+		status int
+		body   bool
+	}{
+		{name: "error only handler", path: "/ping", status: http.StatusNoContent},
+		{name: "nil data", path: "/status", status: http.StatusNoContent},
+		{
+			name:   "error only handler with 200",
+			opts:   []httpbara.ParamsCb{httpbara.WithEmptyResponseStatus(http.StatusOK)},
+			path:   "/ping",
+			status: http.StatusOK,
+		},
+		{
+			name:   "nil data with 200",
+			opts:   []httpbara.ParamsCb{httpbara.WithEmptyResponseStatus(http.StatusOK)},
+			path:   "/status",
+			status: http.StatusOK,
+			body:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &emptyResponseHandler{})}, tt.opts...)
+
+			rec := serve(h, http.MethodGet, tt.path, nil, map[string]string{"Accept": "application/json"})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if !tt.body {
+				if rec.Body.Len() != 0 {
+					t.Fatalf("body = %q, want none", rec.Body.String())
+				}
+
+				return
+			}
+
+			var envelope struct {
+				Data any `json:"data"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if envelope.Data != nil {
+				t.Fatalf("data = %v, want null", envelope.Data)
+			}
+		})
+	}
+}
+
+func TestWithEmptyResponseStatusInvalid(t *testing.T) {
+	if _, err := httpbara.New(nil, httpbara.WithLogger(discardLogger{}), httpbara.WithEmptyResponseStatus(http.StatusAccepted)); err == nil {
+		t.Fatal("expected an error for an empty response status other than 200 or 204")
+	}
+}
@@ -5,12 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/gopybara/httpbara/casual"
+	"maps"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -24,12 +29,48 @@ import (
 // - flatGroups: A map of group names to Group objects. Each Group represents a set of related routes sharing a common prefix and middlewares.
 // - flatMiddlewares: A map of middleware names to Middleware objects. Each middleware can also apply additional middleware.
 // - flatRoutes: A slice of Route objects representing all routes extracted from Handler instances.
+// - routes: A description of every route registered on the Gin engine, filled by applyHandlers.
 type core struct {
 	params
 
 	flatGroups      map[string]*Group
 	flatMiddlewares map[string]*Middleware
 	flatRoutes      []*Route
+	routes          []RouteInfo
+	warnings        []string
+
+	addrsMu sync.RWMutex
+	addrs   []net.Addr
+
+	runMu sync.Mutex
+	run   *runState
+
+	// trackerMu guards taskTracker, which start renews when the engine created it and a previous
+	// shutdown terminated it. trackerStopped is set by that shutdown.
+	trackerMu       sync.RWMutex
+	ownsTaskTracker bool
+	trackerStopped  bool
+
+	parameterizedHandlers map[string]gin.HandlerFunc
+
+	// structValidator validates casual requests once bound, see WithValidator.
+	structValidator binding.StructValidator
+}
+
+// RouteInfo describes a route registered on the engine, e.g. to render a route table.
+//
+// Fields:
+// - `Method`: The HTTP method (e.g., "GET") or "ANY".
+// - `Path`: The full path including the prefixes of the route's group and its parents (e.g., "/api/v3/products/:id").
+// - `Group`: The name of the group the route belongs to, empty if none.
+// - `Middlewares`: The names of the route-level middlewares that were applied, in order.
+// - `Metadata`: The metadata of the route (see RouteMetadata), nil if none. It is shared and must not be modified.
+type RouteInfo struct {
+	Method      string
+	Path        string
+	Group       string
+	Middlewares []string
+	Metadata    map[string]string
 }
 
 // Engine defines the interface for an HTTP engine capable of registering routes, groups, and middleware
@@ -37,12 +78,30 @@ type core struct {
 //
 // Methods:
 // - flatHandlers([]*Handler): Process a collection of Handler objects to flatten their routes, groups, and middleware.
-// - applyHandlers(): Apply all collected routes, groups, and middleware to the underlying Gin engine.
-// - Run(addr string) chan error: Run the HTTP server at the specified address and return a channel for errors.
+// - applyHandlers() error: Apply all collected routes, groups, and middleware to the underlying Gin engine.
+// - Run(addr string) error: Run the HTTP server at the specified address until it fails or is shut down.
+// - RunMulti(configs []ListenConfig) error: Run several HTTP/HTTPS servers sharing the same routes.
+// - Routes() []RouteInfo: Describe all registered routes, available right after New.
+// - RoutesCount() int: Count the registered routes without copying their descriptions.
+// - Warnings() []string: List the unresolved group and middleware references found while registering routes.
+// - AsHTTPHandler() http.Handler: Serve the routes through the standard library, e.g. with httptest.
+// - Addr() net.Addr: The address of the first listener once Run or RunMulti has bound it.
+// - Start(addr string) error: Like Run, but returns once listening instead of waiting for a signal.
+// - StartMulti(configs []ListenConfig) error: Like RunMulti, but returns once listening.
+// - Stop(ctx context.Context) error: Gracefully shut down the servers started by Start or StartMulti.
 type Engine interface {
 	flatHandlers(handlers []*Handler)
-	applyHandlers()
+	applyHandlers() error
 	Run(addr string) error
+	RunMulti(configs []ListenConfig) error
+	Routes() []RouteInfo
+	RoutesCount() int
+	Warnings() []string
+	AsHTTPHandler() http.Handler
+	Addr() net.Addr
+	Start(addr string) error
+	StartMulti(configs []ListenConfig) error
+	Stop(ctx context.Context) error
 }
 
 // New creates a new Engine (core implementation) given a list of Handler objects
@@ -84,29 +143,84 @@ func New(handlers []*Handler, opts ...ParamsCb) (Engine, error) {
 		}
 	}
 
-	// Create a base Gin engine if none was provided
-	if c.gin == nil {
-		err := c.createBaseGin()
+	// Environment variables are layered under the explicit options, which are applied again on top of them
+	if c.envPrefix != "" {
+		envOpts, err := envConfig(c.envPrefix)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create base gin engine: %w", err)
+			return nil, fmt.Errorf("failed to read env config: %w", err)
 		}
-	}
 
-	if c.casualResponseHandler == nil {
-		c.casualResponseHandler = defaultCasualResponder[any]
+		c.params = params{shutdownTimeout: 30 * time.Second}
+		for _, opt := range append(envOpts, opts...) {
+			err := opt(&c.params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply option: %w", err)
+			}
+		}
 	}
 
 	if c.casualResponseErrorHandler == nil {
 		c.casualResponseErrorHandler = defaultCasualErrorResponder
 	}
 
+	if c.validator != nil {
+		c.structValidator = &structValidator{validate: c.validator}
+	}
+
+	if len(c.translators) > 0 {
+		errorHandler := c.casualResponseErrorHandler
+		c.casualResponseErrorHandler = func(err error, opts ...casual.HttpResponseParamsCb) (int, interface{}) {
+			return errorHandler(err, append(opts, casual.WithTranslators(c.translators...))...)
+		}
+	}
+
+	if c.responseEncoders == nil {
+		c.responseEncoders = defaultResponseEncoders()
+	}
+
+	if c.recoverHandler == nil {
+		c.recoverHandler = c.defaultRecoverHandler
+	}
+
+	if c.emptyResponseStatus == 0 {
+		c.emptyResponseStatus = http.StatusNoContent
+	}
+
+	if c.taskTracker == nil {
+		c.taskTracker = NewActiveTaskTracker()
+		c.ownsTaskTracker = true
+	}
+
 	// Set a default logger if none provided
 	if c.log == nil {
 		c.log = NewFmtLogger()
 	}
 
+	c.applyGinMode()
+
+	// Create a base Gin engine if none was provided
+	if c.gin == nil {
+		err := c.createBaseGin()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create base gin engine: %w", err)
+		}
+	} else {
+		c.applyGinEngineRecovery()
+	}
+
+	c.applyRedirects()
+
+	if c.casualResponseHandler == nil {
+		c.casualResponseHandler = defaultCasualResponder[any]
+	}
+
 	c.flatHandlers(handlers)
-	c.applyHandlers()
+	if err := c.applyHandlers(); err != nil {
+		return nil, fmt.Errorf("failed to apply handlers: %w", err)
+	}
+
+	c.applyMethodNotAllowed()
+	c.applyNoRoute()
 
 	return c, nil
 }
@@ -117,10 +231,33 @@ func New(handlers []*Handler, opts ...ParamsCb) (Engine, error) {
 // Parameters:
 // - handlers: A slice of Handler objects, each containing discovered routes, groups, and middleware.
 //
+// Groups registered through WithGroups are merged last.
+//
 // After this method is called, `flatGroups`, `flatMiddlewares`, and `flatRoutes` will be populated.
 func (c *core) flatHandlers(handlers []*Handler) {
+	// Groups are merged first, so casual routes can resolve the responders of their group and its ancestors
+	for _, handler := range handlers {
+		for _, group := range handler.groups {
+			c.flatGroups[group.name] = group
+		}
+	}
+
+	for _, group := range c.groups {
+		c.flatGroups[group.name] = group
+	}
+
 	for _, handler := range handlers {
-		c.flatRoutes = append(c.flatRoutes, handler.routes...)
+		for _, name := range handler.unimplementedRoutes {
+			c.warn("skipping route because the handler has no method with its name", "route", name)
+		}
+
+		for _, route := range handler.routes {
+			if route.websocket != nil {
+				route = c.websocketRoute(route)
+			}
+
+			c.flatRoutes = append(c.flatRoutes, route)
+		}
 
 		for _, casualR := range handler.casualRoutes {
 			useGinContext := false
@@ -128,113 +265,184 @@ func (c *core) flatHandlers(handlers []*Handler) {
 				useGinContext = true
 			}
 
-			reqType := casualR.handler.rm.Type.In(2)
+			reqType := casualR.handler.requestType()
+
+			defaultStatusCode := http.StatusOK
+			if casualR.status != 0 {
+				defaultStatusCode = casualR.status
+			} else if code, ok := c.methodDefaultStatus[strings.ToUpper(casualR.method)]; ok {
+				defaultStatusCode = code
+			}
+
+			successResponder, listResponder, errorResponder := c.respondersOf(casualR.group)
 
 			cb := func(ctx *gin.Context) {
-				rcb := getResponseCallback(ctx)
+				rcb := c.getResponseCallback(ctx)
+				langCbs := languageParams(ctx)
 
 				var ct = ctx.Request.Context()
 				if useGinContext {
 					ct = ctx
 				}
 
-				reqVal, err := dynamicBind(ctx, reqType)
-				if err != nil {
-					rcb(c.casualResponseErrorHandler(err))
-					ctx.Abort()
-					return
-				}
+				callArgs := []reflect.Value{*casualR.handler.rv, reflect.ValueOf(ct)}
 
-				var arg reflect.Value
-				switch reqType.Kind() {
-				case reflect.Struct:
-					// handler.Handle(ctx, req contracts.PublishEventsEvent[…])
-					// ждёт сам struct, разворачиваем указатель
-					arg = reqVal.Elem()
-				case reflect.Ptr:
-					// handler.Handle(ctx, req *contracts.PublishEventsEvent[…])
-					// ждёт pointer, передаём reqVal
-					arg = reqVal
-				default:
-					c.log.Panic("unexpected reqType kind", "kind", reqType.Kind().String())
+				// Handlers taking only a context have nothing to bind
+				if reqType != nil {
+					reqVal, err := c.dynamicBind(ctx, reqType)
+					if err != nil {
+						rcb(errorResponder(err, langCbs...))
+						ctx.Abort()
+						return
+					}
+
+					var arg reflect.Value
+					switch reqType.Kind() {
+					case reflect.Struct:
+						// handler.Handle(ctx, req contracts.PublishEventsEvent[…])
+						// ждёт сам struct, разворачиваем указатель
+						arg = reqVal.Elem()
+					case reflect.Ptr:
+						// handler.Handle(ctx, req *contracts.PublishEventsEvent[…])
+						// ждёт pointer, передаём reqVal
+						arg = reqVal
+					default:
+						c.log.Error("unexpected reqType kind", "kind", reqType.Kind().String())
+						rcb(errorResponder(casual.ErrInternalServerError, langCbs...))
+						ctx.Abort()
+						return
+					}
+
+					callArgs = append(callArgs, arg)
 				}
 
-				respArr := casualR.handler.rm.Func.Call([]reflect.Value{*casualR.handler.rv, reflect.ValueOf(ct), arg})
+				respArr := casualR.handler.rm.Func.Call(callArgs)
+
+				// (data, http.Header, error): the headers are written before the response,
+				// on errors too, and the rest is handled like the (data, error) form
+				if len(respArr) == 3 {
+					for key, values := range respArr[1].Interface().(http.Header) {
+						for _, value := range values {
+							ctx.Writer.Header().Add(key, value)
+						}
+					}
+
+					respArr = []reflect.Value{respArr[0], respArr[2]}
+				}
 
-				statusCode := http.StatusOK
-				if respArr[0].MethodByName("StatusCode").IsValid() {
+				statusCode := defaultStatusCode
+				if !isNilData(respArr[0]) && respArr[0].MethodByName("StatusCode").IsValid() {
 					values := respArr[0].MethodByName("StatusCode").Call([]reflect.Value{})
 					statusCode = values[0].Interface().(int)
 				}
 
-				paramsCbs := []casual.HttpResponseParamsCb{
+				paramsCbs := append([]casual.HttpResponseParamsCb{
 					casual.WithHttpStatusCode(statusCode),
-				}
+				}, langCbs...)
 
 				switch len(respArr) {
 				case 1:
 					if respArr[0].IsNil() {
-						ctx.AbortWithStatus(statusCode)
+						ctx.AbortWithStatus(c.emptyStatus(statusCode))
 						return
 					}
 
-					rcb(c.params.casualResponseErrorHandler(respArr[0].Interface().(error)))
+					err := timeoutError(ctx, respArr[0].Interface().(error))
+					rcb(errorResponder(err, errorMetaParams(err, langCbs)...))
 					ctx.Abort()
 					return
 				case 2:
-					if respArr[1].IsNil() {
-						if !respArr[1].IsNil() {
-							rcb(c.casualResponseErrorHandler(respArr[1].Interface().(error)))
-							ctx.Abort()
-							return
-						}
-						if respArr[0].MethodByName("Meta").IsValid() &&
-							respArr[0].MethodByName("Meta").Type().NumIn() == 0 &&
-							respArr[0].MethodByName("Meta").Type().NumOut() == 1 &&
-							respArr[0].MethodByName("Meta").Type().Out(0).Kind() == reflect.Map {
-							values := respArr[0].MethodByName("Meta").Call([]reflect.Value{})
-							dataMap := make(map[string]interface{})
+					// A returned error takes precedence over the data returned alongside it
+					if !respArr[1].IsNil() {
+						err := timeoutError(ctx, respArr[1].Interface().(error))
+						rcb(errorResponder(err, errorMetaParams(err, langCbs)...))
+						ctx.Abort()
+						return
+					}
+
+					if isNilData(respArr[0]) && c.emptyStatus(statusCode) == http.StatusNoContent {
+						ctx.AbortWithStatus(http.StatusNoContent)
+						return
+					}
+
+					if reader, ok := readerResponse(respArr[0]); ok {
+						serveReader(ctx, statusCode, reader)
+						ctx.Abort()
+						return
+					}
 
-							next := values[0].MapRange()
+					// A nil pointer is only rendered as empty data, its methods may dereference it
+					hasData := !isNilData(respArr[0])
 
-							for {
-								if !next.Next() {
-									break
-								}
+					var meta map[string]interface{}
+					if hasData && respArr[0].MethodByName("Meta").IsValid() &&
+						respArr[0].MethodByName("Meta").Type().NumIn() == 0 &&
+						respArr[0].MethodByName("Meta").Type().NumOut() == 1 &&
+						respArr[0].MethodByName("Meta").Type().Out(0).Kind() == reflect.Map {
+						values := respArr[0].MethodByName("Meta").Call([]reflect.Value{})
+						dataMap := make(map[string]interface{})
 
-								dataMap[next.Key().String()] = next.Value().Interface()
+						next := values[0].MapRange()
+
+						for {
+							if !next.Next() {
+								break
 							}
 
-							paramsCbs = append(paramsCbs, casual.WithMeta(dataMap))
+							dataMap[next.Key().String()] = next.Value().Interface()
 						}
 
-						rcb(c.params.casualResponseHandler(respArr[0].Interface(), paramsCbs...))
-						ctx.Abort()
-					} else {
-						rcb(c.params.casualResponseErrorHandler(respArr[1].Interface().(error)))
-						ctx.Abort()
-						return
+						meta = dataMap
+						paramsCbs = append(paramsCbs, casual.WithMeta(dataMap))
 					}
-				default:
-					c.log.Panic(
-						"casual handler returned more than two values",
-						"handler", casualR.handler.rm.Name,
-						"route", casualR.path,
-						"method", casualR.method)
+
+					if hasData && respArr[0].MethodByName("Location").IsValid() &&
+						respArr[0].MethodByName("Location").Type().NumIn() == 0 &&
+						respArr[0].MethodByName("Location").Type().NumOut() == 1 &&
+						respArr[0].MethodByName("Location").Type().Out(0).Kind() == reflect.String {
+						values := respArr[0].MethodByName("Location").Call([]reflect.Value{})
+
+						paramsCbs = append(paramsCbs, casual.WithLocation(values[0].String()))
+					}
+
+					responder := successResponder
+					if listResponder != nil && respArr[0].Kind() == reflect.Slice {
+						responder = listResponder
+					}
+
+					code, obj := responder(respArr[0].Interface(), paramsCbs...)
+					if l, ok := obj.(locator); ok && l.Location() != "" {
+						ctx.Header("Location", l.Location())
+					}
+
+					if c.params.totalCountHeader && (respArr[0].Kind() == reflect.Slice || respArr[0].Kind() == reflect.Array) {
+						var total any = respArr[0].Len()
+						if t, ok := meta["total"]; ok {
+							total = t
+						}
+
+						ctx.Header(casual.TotalCountHeader, fmt.Sprint(total))
+					}
+
+					rcb(code, obj)
+					ctx.Abort()
 				}
 			}
 
 			c.flatRoutes = append(c.flatRoutes, &Route{
+				name:        casualR.name,
 				method:      casualR.method,
 				path:        casualR.path,
 				handler:     cb,
 				middlewares: casualR.middlewares,
 				group:       casualR.group,
-			})
-		}
+				constraints: casualR.constraints,
+				consumes:    casualR.consumes,
+				metadata:    casualR.metadata,
 
-		for _, group := range handler.groups {
-			c.flatGroups[group.name] = group
+				timeout:        casualR.timeout,
+				invalidTimeout: casualR.invalidTimeout,
+			})
 		}
 
 		for _, middleware := range handler.middlewares {
@@ -243,7 +451,12 @@ func (c *core) flatHandlers(handlers []*Handler) {
 	}
 }
 
-func dynamicBind(ctx *gin.Context, reqType reflect.Type) (reflect.Value, error) {
+// dynamicBind creates a new value of the casual request type and binds the request into it:
+// path parameters into fields tagged `uri:"name"`, query parameters in bracket notation into nested fields,
+// then the body choosing the binding by content type. GET, HEAD and DELETE requests without a JSON, XML or YAML
+// body bind the query into fields tagged `form:"name"`, e.g. `?page=2&limit=10`. The bound value is validated
+// last, with the validator set via WithValidator or Gin's binding validator.
+func (c *core) dynamicBind(ctx *gin.Context, reqType reflect.Type) (reflect.Value, error) {
 	base := reqType
 	for base.Kind() == reflect.Ptr {
 		base = base.Elem()
@@ -261,100 +474,257 @@ func dynamicBind(ctx *gin.Context, reqType reflect.Type) (reflect.Value, error)
 
 	switch {
 	case strings.HasSuffix(contentType, "json"):
-		binder = ctx.ShouldBindJSON
+		binder = func(obj interface{}) error {
+			return decodeJSON(ctx, obj, c.strictJSON)
+		}
 	case strings.HasSuffix(contentType, "xml"):
-		binder = ctx.ShouldBindXML
+		binder = func(obj interface{}) error {
+			return decodeXML(ctx, obj)
+		}
 	case strings.HasSuffix(contentType, "yaml"):
-		binder = ctx.ShouldBindYAML
+		binder = func(obj interface{}) error {
+			return decodeYAML(ctx, obj)
+		}
+	case ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead || ctx.Request.Method == http.MethodDelete:
+		// Requests without a body bind the query explicitly, rather than relying on the form binding to read it
+		binder = func(obj interface{}) error {
+			return binding.MapFormWithTag(obj, ctx.Request.URL.Query(), "form")
+		}
 	default:
-		binder = ctx.ShouldBind
+		switch b := binding.Default(ctx.Request.Method, contentType); b {
+		case binding.Form:
+			binder = func(obj interface{}) error {
+				return decodeForm(ctx, obj)
+			}
+		case binding.FormMultipart:
+			binder = func(obj interface{}) error {
+				return decodeMultipartForm(ctx, obj)
+			}
+		case binding.TOML:
+			binder = func(obj interface{}) error {
+				return decodeTOML(ctx, obj)
+			}
+		default:
+			binder = func(obj interface{}) error {
+				return decodeBody(ctx, obj, b)
+			}
+		}
+	}
+
+	if err := bindUri(ctx, reqPtr.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+
+	if err := bindBracketQuery(ctx, reqPtr.Interface()); err != nil {
+		return reflect.Value{}, err
 	}
 
 	if err := binder(reqPtr.Interface()); err != nil {
 		return reflect.Value{}, err
 	}
 
+	if err := c.validate(reqPtr.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+
 	return reqPtr, nil
 }
 
+// validate validates a bound casual request with the validator set via WithValidator. Engines without one
+// use Gin's binding validator, so that replacing it process-wide still applies to them.
+func (c *core) validate(obj interface{}) error {
+	if c.structValidator != nil {
+		return c.structValidator.ValidateStruct(obj)
+	}
+
+	if binding.Validator == nil {
+		return nil
+	}
+
+	return binding.Validator.ValidateStruct(obj)
+}
+
 type responseCallback func(code int, obj any)
 
-func getResponseCallback(ctx *gin.Context) responseCallback {
-	switch ctx.GetHeader("Accept") {
-	case "application/xml":
-		return ctx.XML
+// emptyStatus returns the status code of an empty casual response given the success status code of the route,
+// see WithEmptyResponseStatus.
+func (c *core) emptyStatus(statusCode int) int {
+	if statusCode != http.StatusOK {
+		return statusCode
+	}
+
+	return c.emptyResponseStatus
+}
+
+// isNilData reports whether the data returned by a casual handler is a nil pointer or interface.
+func isNilData(data reflect.Value) bool {
+	switch data.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return data.IsNil()
 	default:
-		return ctx.JSON
+		return false
 	}
 }
 
+// metaError is implemented by errors carrying metadata for the `meta` of the error envelope.
+type metaError interface {
+	error
+	Meta() map[string]interface{}
+}
+
+// errorMetaParams returns the response params passing the metadata of err, or of an error it wraps,
+// to the casual error responder. It returns opts unchanged when there is no such error.
+func errorMetaParams(err error, opts []casual.HttpResponseParamsCb) []casual.HttpResponseParamsCb {
+	var me metaError
+	if !errors.As(err, &me) {
+		return opts
+	}
+
+	meta := me.Meta()
+	if len(meta) == 0 {
+		return opts
+	}
+
+	return append(slices.Clip(opts), casual.WithMeta(meta))
+}
+
+// locator is implemented by response envelopes that carry the location of a created resource,
+// which the dispatch sends as the `Location` header.
+type locator interface {
+	Location() string
+}
+
 // applyHandlers goes through all flattened routes and applies them to the Gin engine.
 // It reconstructs the full path by combining group prefixes (if any) and sets up the middleware stack.
-// Middleware can be defined at the group level and at the route level. If a route belongs to a group,
-// the group's middleware is applied first, followed by the route's middleware.
+// Middlewares run in a fixed order: the root middlewares (see WithRootMiddlewares), then the middlewares
+// of the route's groups, then the route's own middlewares, each level in declaration order. A middleware
+// declaring middlewares of its own (its `middlewares` tag) is preceded by them, on every level. A middleware
+// already in the stack is skipped, unless WithAllowDuplicateMiddleware was provided.
+//
+// Groups can be nested through the `parent` tag; the prefixes and middleware of all ancestors are applied
+// outermost-first. A cycle in the parent chain is reported as an error.
+//
+// The base path set through WithBasePath is prepended to the resolved path.
+//
+// Context initializers provided through WithContextInitializer run first on every route.
 //
-// This method also logs warnings if a specified group or middleware cannot be found,
+// Routes with a `timeout` tag are wrapped, before any other middleware, with a handler that bounds the request context.
+//
+// This method also logs warnings, recorded for Warnings, if a specified group or middleware cannot be found,
 // and logs info messages about successful route registrations.
-func (c *core) applyHandlers() {
+func (c *core) applyHandlers() error {
+	rootStack, rootSeen, err := c.rootMiddlewareStack()
+	if err != nil {
+		return err
+	}
+
 	for _, route := range c.flatRoutes {
 		path := route.path
-		handleStack := make([]gin.HandlerFunc, 0)
-		for _, mw := range c.rootMiddlewares {
-			for _, middleware := range mw.middlewares {
-				handleStack = append(handleStack, middleware.handler)
-			}
+		handleStack := append(slices.Clone(c.contextInitializers), c.failureHandler(), c.taskTrackerHandler())
+		if c.serverTiming {
+			handleStack = append(handleStack, serverTimingMiddleware)
+		}
+
+		if route.timeout > 0 {
+			handleStack = append(handleStack, c.timeoutMiddleware(route.timeout))
+		} else if route.invalidTimeout != "" {
+			c.warn("skipping route timeout because it is not a valid duration",
+				"route", route.path,
+				"timeout", route.invalidTimeout,
+			)
 		}
 
-		// Apply group prefix and group-level middleware if route has a group
+		handleStack = append(handleStack, rootStack...)
+		seen := maps.Clone(rootSeen)
+
+		// Apply group prefixes and group-level middleware if route has a group,
+		// walking the parent chain from the outermost group to the route's own group
 		if route.group != "" {
-			if group, ok := c.flatGroups[route.group]; ok {
-				path = strings.TrimSuffix(group.Path, "/") + "/" + strings.TrimPrefix(path, "/")
-
-				for _, m := range group.middlewares {
-					if mw, mwOk := c.flatMiddlewares[m]; mwOk {
-						handleStack = append(handleStack, mw.handler)
-					} else {
-						c.log.Warn("skipping group middleware because there is no middleware with this name",
-							"middlewareToSkip", m,
-							"group", route.group,
-						)
+			if _, ok := c.flatGroups[route.group]; ok {
+				chain, err := c.groupChain(route.group)
+				if err != nil {
+					return fmt.Errorf("failed to resolve group of route %s %s: %w", route.method, route.path, err)
+				}
+
+				for _, group := range chain {
+					for _, m := range group.middlewares {
+						name, arg, hasArg := splitMiddlewareEntry(m)
+						if mw, mwOk := c.flatMiddlewares[name]; mwOk {
+							stack, err := c.middlewareStack(mw, arg, hasArg, c.flatMiddlewares, seen, "group", group.name)
+							if err != nil {
+								return fmt.Errorf("failed to apply middlewares of group %s: %w", group.name, err)
+							}
+
+							handleStack = append(handleStack, stack...)
+						} else {
+							c.warn("skipping group middleware because there is no middleware with this name",
+								"middlewareToSkip", m,
+								"group", group.name,
+							)
+						}
 					}
 				}
+
+				path = joinGroupPath(chain, path)
 			} else {
-				c.log.Warn("skipping group because group was not found",
+				c.warn("skipping group because group was not found",
 					"path", route.path,
 					"group", route.group,
 				)
 			}
 		}
 
+		if c.basePath != "" {
+			path = joinPath(c.basePath, path)
+		}
+
+		if c.trimSlashes {
+			path = trimTrailingSlashes(path)
+		}
+
+		if c.pathTransformer != nil {
+			path = c.pathTransformer(path)
+		}
+
+		metadata := c.routeMetadataOf(route, path)
+		if len(metadata) > 0 {
+			handleStack = slices.Insert(handleStack, len(c.contextInitializers)+2, routeMetadataHandler(metadata))
+		}
+
 		var appliedMiddlewares []string
 		for _, middleware := range route.middlewares {
-			if mw, ok := c.flatMiddlewares[middleware]; ok {
-				appliedMiddlewares = append(appliedMiddlewares, mw.middleware)
-
-				// Some middleware can apply additional middleware
-				for _, m := range mw.middlewares {
-					if mw2, mw2ok := c.flatMiddlewares[m]; mw2ok {
-						handleStack = append(handleStack, mw2.handler)
-					} else {
-						c.log.Warn("skipping middleware of middleware because there is no middleware with this name",
-							"route", path,
-							"middlewareToSkip", m,
-							"parentMiddleware", mw.middleware,
-						)
-					}
+			name, arg, hasArg := splitMiddlewareEntry(middleware)
+			if mw, ok := c.flatMiddlewares[name]; ok {
+				if !seen.has(mw) {
+					appliedMiddlewares = append(appliedMiddlewares, mw.middleware)
+				}
+
+				stack, err := c.middlewareStack(mw, arg, hasArg, c.flatMiddlewares, seen, "route", path)
+				if err != nil {
+					return fmt.Errorf("failed to apply middlewares of route %s %s: %w", route.method, path, err)
 				}
 
-				handleStack = append(handleStack, mw.handler)
+				handleStack = append(handleStack, stack...)
 			} else {
-				c.log.Warn("skipping route middleware because there is no middleware with this name",
+				c.warn("skipping route middleware because there is no middleware with this name",
 					"route", path,
 					"middlewareToSkip", middleware,
 				)
 			}
 		}
 
+		if len(route.constraints) > 0 {
+			if err := checkConstraintParams(route.constraints, path); err != nil {
+				return fmt.Errorf("invalid constraints of route %s %s: %w", route.method, path, err)
+			}
+
+			handleStack = append(handleStack, c.constraintsMiddleware(route.constraints))
+		}
+
+		if len(route.consumes) > 0 {
+			handleStack = append(handleStack, c.consumesMiddleware(route.consumes))
+		}
+
 		handleStack = append(handleStack, route.handler)
 
 		if route.method == "ANY" {
@@ -363,87 +733,369 @@ func (c *core) applyHandlers() {
 			c.gin.Handle(route.method, path, handleStack...)
 		}
 
+		c.routes = append(c.routes, RouteInfo{
+			Method:      route.method,
+			Path:        path,
+			Group:       route.group,
+			Middlewares: appliedMiddlewares,
+			Metadata:    metadata,
+		})
+
 		c.log.Info("route was registered",
 			"method", route.method,
 			"route", path,
 			"middlewares", appliedMiddlewares,
 		)
 	}
+
+	return nil
+}
+
+// Routes returns a description of every route registered on the Gin engine, in registration order.
+// The returned slice is a copy and can be modified freely.
+func (c *core) Routes() []RouteInfo {
+	routes := make([]RouteInfo, len(c.routes))
+	copy(routes, c.routes)
+
+	return routes
+}
+
+// RoutesCount returns the number of routes registered on the Gin engine. Routes registered with the `ANY`
+// method count once. Unlike Routes it does not copy the route descriptions.
+func (c *core) RoutesCount() int {
+	return len(c.routes)
+}
+
+// Warnings returns the warnings logged while registering routes, such as references to groups or middlewares
+// that do not exist, in the order they were logged. Each warning is the log message followed by its key-value
+// fields. The returned slice is a copy and can be modified freely.
+//
+// **Example:**
+// ```go
+//
+//	if warnings := engine.Warnings(); len(warnings) > 0 {
+//	    t.Fatalf("unexpected route warnings: %v", warnings)
+//	}
+//
+// ```
+func (c *core) Warnings() []string {
+	warnings := make([]string, len(c.warnings))
+	copy(warnings, c.warnings)
+
+	return warnings
+}
+
+// warn logs a warning about the route configuration and records it for Warnings.
+func (c *core) warn(message string, args ...any) {
+	c.log.Warn(message, args...)
+
+	var sb strings.Builder
+	sb.WriteString(message)
+	for i := 0; i+1 < len(args); i += 2 {
+		sb.WriteString(fmt.Sprintf(" %v=%v", args[i], args[i+1]))
+	}
+
+	c.warnings = append(c.warnings, sb.String())
+}
+
+// rootMiddlewareStack returns the handlers of the root middlewares, in the order of the handlers passed to
+// WithRootMiddlewares and of the middlewares declared within each of them, and the set of their names.
+// Middlewares of root middlewares are looked up in their own handler first and among the middlewares
+// of the engine handlers otherwise.
+func (c *core) rootMiddlewareStack() ([]gin.HandlerFunc, middlewareSet, error) {
+	var stack []gin.HandlerFunc
+
+	seen := make(middlewareSet)
+	if c.allowDuplicateMiddleware {
+		seen = nil
+	}
+
+	for _, root := range c.rootMiddlewares {
+		middlewares := maps.Clone(c.flatMiddlewares)
+		for _, mw := range root.middlewares {
+			middlewares[strings.ToLower(mw.middleware)] = mw
+		}
+
+		for _, mw := range root.middlewares {
+			mwStack, err := c.middlewareStack(mw, "", false, middlewares, seen, "rootMiddleware", mw.middleware)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to apply root middlewares: %w", err)
+			}
+
+			stack = append(stack, mwStack...)
+		}
+	}
+
+	return stack, seen, nil
+}
+
+// middlewareStack returns the handler of mw for the given argument (see middlewareHandler) preceded by the
+// handlers of the middlewares it declares, resolved in middlewares. Unknown ones are skipped with a warning
+// carrying the given args, and so are the ones already in seen, which the returned ones are added to.
+func (c *core) middlewareStack(mw *Middleware, arg string, hasArg bool, middlewares map[string]*Middleware, seen middlewareSet, args ...any) ([]gin.HandlerFunc, error) {
+	stack := make([]gin.HandlerFunc, 0, len(mw.middlewares)+1)
+
+	for _, m := range mw.middlewares {
+		name, depArg, depHasArg := splitMiddlewareEntry(m)
+		if dependency, ok := middlewares[name]; ok {
+			if seen.add(dependency) {
+				handler, err := c.middlewareHandler(dependency, depArg, depHasArg, args...)
+				if err != nil {
+					return nil, err
+				}
+
+				stack = append(stack, handler)
+			}
+		} else {
+			c.warn("skipping middleware of middleware because there is no middleware with this name",
+				append(args, "middlewareToSkip", m, "parentMiddleware", mw.middleware)...,
+			)
+		}
+	}
+
+	if seen.add(mw) {
+		handler, err := c.middlewareHandler(mw, arg, hasArg, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		stack = append(stack, handler)
+	}
+
+	return stack, nil
+}
+
+// middlewareSet holds the names of the middlewares already in a handler stack, so that a middleware
+// declared on several levels runs once, at its earliest position. A nil set lets duplicates through,
+// see WithAllowDuplicateMiddleware.
+type middlewareSet map[string]struct{}
+
+// has reports whether mw is in the set.
+func (s middlewareSet) has(mw *Middleware) bool {
+	_, ok := s[strings.ToLower(mw.middleware)]
+
+	return ok
+}
+
+// add adds mw to the set, reporting false when it was there already.
+func (s middlewareSet) add(mw *Middleware) bool {
+	if s == nil {
+		return true
+	} else if s.has(mw) {
+		return false
+	}
+
+	s[strings.ToLower(mw.middleware)] = struct{}{}
+
+	return true
+}
+
+// joinGroupPath prefixes path with the paths of the given group chain, outermost first, see joinPath.
+func joinGroupPath(chain []*Group, path string) string {
+	elems := make([]string, 0, len(chain)+1)
+	for _, group := range chain {
+		elems = append(elems, group.Path)
+	}
+
+	return joinPath(append(elems, path)...)
+}
+
+// joinPath joins path elements with single slashes: duplicate slashes are collapsed and the result always
+// starts with a single slash, so empty elements and "/" add nothing. A trailing slash of the last non-empty
+// element is kept, e.g. the route "/" in the group "/api" is "/api/".
+func joinPath(elems ...string) string {
+	var sb strings.Builder
+	trailingSlash := false
+
+	for _, elem := range elems {
+		if elem == "" {
+			continue
+		}
+
+		for _, segment := range strings.Split(elem, "/") {
+			if segment != "" {
+				sb.WriteString("/")
+				sb.WriteString(segment)
+			}
+		}
+
+		trailingSlash = strings.HasSuffix(elem, "/")
+	}
+
+	if sb.Len() == 0 || trailingSlash {
+		sb.WriteString("/")
+	}
+
+	return sb.String()
+}
+
+// trimTrailingSlashes removes all trailing slashes from a route path, keeping the root path "/".
+func trimTrailingSlashes(path string) string {
+	trimmed := strings.TrimRight(path, "/")
+	if trimmed == "" {
+		return "/"
+	}
+
+	return trimmed
+}
+
+// groupChain returns the group with the given name preceded by all of its ancestors, outermost first.
+// A parent that cannot be found ends the chain with a warning, a cycle results in ErrGroupCycle.
+func (c *core) groupChain(name string) ([]*Group, error) {
+	chain := make([]*Group, 0)
+	visited := make(map[string]struct{})
+
+	for name != "" {
+		if _, ok := visited[name]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrGroupCycle, name)
+		}
+		visited[name] = struct{}{}
+
+		group, ok := c.flatGroups[name]
+		if !ok {
+			c.warn("skipping parent group because group was not found",
+				"group", name,
+			)
+			break
+		}
+
+		chain = append([]*Group{group}, chain...)
+		name = group.parent
+	}
+
+	return chain, nil
 }
 
 // createBaseGin initializes a new default Gin engine with standard middleware (like Recovery).
 // If a custom Gin instance was not provided via parameters, this method ensures there's at least
-// a basic setup to work with.
+// a basic setup to work with. Panics are logged and passed to the handler configured via WithRecovery.
 //
 // Returns:
 // - error: If initialization fails for some reason (unlikely).
 func (c *core) createBaseGin() error {
 	c.gin = gin.New()
-	c.gin.Use(gin.Recovery())
+	c.gin.Use(c.recovery())
 
 	return nil
 }
 
+// applyRedirects sets the redirect behavior of the Gin engine configured via WithTrailingSlashRedirect
+// and WithFixedPathRedirect, keeping the engine defaults otherwise.
+func (c *core) applyRedirects() {
+	if c.trailingSlashRedirect != nil {
+		c.gin.RedirectTrailingSlash = *c.trailingSlashRedirect
+	}
+
+	if c.fixedPathRedirect != nil {
+		c.gin.RedirectFixedPath = *c.fixedPathRedirect
+	}
+}
+
 // Run starts the HTTP server on the given address using the underlying Gin engine.
-// It returns a channel of errors, allowing the caller to handle any runtime server errors asynchronously.
+// When WithListener or WithUnixSocket was provided, the server serves that listener or socket and addr is ignored.
+// When WithTLS or WithTLSConfig was provided, the server serves HTTPS.
+// It blocks until the server fails or a termination signal (SIGINT, SIGTERM) is received,
+// in which case the server is shut down gracefully. Use Start and Stop to handle signals yourself.
 //
 // Parameters:
-// - addr: The address to listen on, e.g., ":8080" for port 8080.
+// - addr: The address to listen on, e.g., ":8080" for port 8080. When empty, the address configured
+// through WithEnvConfig is used, or a port chosen by the system if there is none (see Addr).
 //
 // Returns:
-// - chan error: A channel that will receive any error that occurs when running the server.
+// - error: Any error that occurred while starting, running or shutting down the server.
 //
 // Example:
 // ```go
 // engine, _ := New(handlers)
-// errChan := engine.Run(":8080")
 //
-//	if err := <-errChan; err != nil {
+//	if err := engine.Run(":8080"); err != nil {
 //	    log.Fatal("server error:", err)
 //	}
 //
 // ```
 func (c *core) Run(addr string) error {
-	errChan := make(chan error)
-	srv := &http.Server{
-		Addr:    addr,
-		Handler: c.gin,
-	}
-
-	go func() {
-		errChan <- func() error {
-			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-				return err
-			}
+	return c.RunMulti([]ListenConfig{c.listenConfig(addr)})
+}
 
-			return nil
-		}()
-	}()
+// RunMulti starts one HTTP server per listener config, all sharing the same routes, and blocks
+// until one of them fails or a termination signal is received. Every address is bound before
+// any server starts serving, so a collision fails fast without accepting traffic. When one server
+// fails, the remaining servers are shut down gracefully and all errors are aggregated. Servers registered
+// with WithServers are started and shut down together with the listeners. On a termination signal the
+// servers keep serving for the delay configured via WithPreShutdownDelay before they are shut down.
+// Shutdown waits up to the shutdown timeout for every in-flight request counted by the task tracker,
+// and requests arriving once it has begun are answered with 503. Use StartMulti and Stop to handle signals yourself.
+//
+// Example:
+// ```go
+//
+//	err := engine.RunMulti([]ListenConfig{
+//	    {Addr: ":8080"},
+//	    {Addr: ":8443", CertFile: "server.crt", KeyFile: "server.key"},
+//	})
+//
+// ```
+func (c *core) RunMulti(configs []ListenConfig) error {
+	rs, err := c.start(configs)
+	if err != nil {
+		return err
+	}
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	signaled := false
 
 	select {
-	case err := <-errChan:
-		if err != nil {
-			return fmt.Errorf("server failed to start: %w", err)
-		}
+	case <-rs.failed:
+	case <-rs.stopped:
+		return rs.stopErr
 	case sig := <-quit:
 		c.log.Info("shutting down server", "signal", sig)
+		signaled = true
+	}
 
-		ctx, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout)
-		defer cancel()
+	// Stop may have been called meanwhile, in which case it reports the shutdown
+	if !c.takeRun(rs) {
+		<-rs.stopped
+		return rs.stopErr
+	}
 
-		if err := srv.Shutdown(ctx); err != nil {
-			return fmt.Errorf("server shutdown failed: %w", err)
-		}
+	if signaled {
+		c.preShutdown(context.Background(), rs)
+	}
 
-		if c.taskTracker != nil {
-			if err := c.taskTracker.Shutdown(ctx); err != nil {
-				return fmt.Errorf("task tracker shutdown failed: %w", err)
-			}
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout)
+	defer cancel()
+
+	return c.shutdown(ctx, rs)
+}
+
+// AsHTTPHandler returns the http.Handler served by Run and RunMulti: the Gin engine with every route registered,
+// wrapped by the handlers registered via WithHandlerWrapper. It is safe to call right after New, and lets the
+// engine be used without starting a server, e.g. with httptest or inside another http.Handler chain.
+// Shutdown handling is not involved, as it is bound to Run and RunMulti.
+//
+// **Example:**
+// ```go
+//
+//	engine, _ := httpbara.New(handlers)
+//	srv := httptest.NewServer(engine.AsHTTPHandler())
+//	defer srv.Close()
+//
+// ```
+func (c *core) AsHTTPHandler() http.Handler {
+	return c.handler()
+}
+
+// handler returns the Gin engine wrapped by every handler wrapper registered via WithHandlerWrapper.
+// Wrappers are applied in registration order, so the first registered wrapper is the innermost one.
+func (c *core) handler() http.Handler {
+	var handler http.Handler = c.gin
+	for _, wrapper := range c.handlerWrappers {
+		handler = wrapper(handler)
 	}
 
-	return nil
+	return handler
 }
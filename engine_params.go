@@ -1,20 +1,72 @@
 package httpbara
 
 import (
+	"crypto/tls"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
 	"github.com/gopybara/httpbara/casual"
+	"github.com/gorilla/websocket"
+	"net"
+	"net/http"
+	"os"
+	"strings"
 	"time"
 )
 
 type params struct {
-	gin             *gin.Engine
-	log             Logger
-	rootMiddlewares []*Handler
-	shutdownTimeout time.Duration
-	taskTracker     TaskTracker
+	gin              *gin.Engine
+	log              Logger
+	rootMiddlewares  []*Handler
+	shutdownTimeout  time.Duration
+	preShutdownDelay time.Duration
+	taskTracker      TaskTracker
+	unixSocket       string
+	tlsCertFile      string
+	tlsKeyFile       string
+	tlsConfig        *tls.Config
+	servers          []Server
+	handlerWrappers  []func(http.Handler) http.Handler
+	recoverHandler   RecoverHandler
+	shutdownState    *ShutdownState
+	serverTiming     bool
+	totalCountHeader bool
+	strictJSON       bool
+	trimSlashes      bool
+	pathTransformer  func(path string) string
+	maxConnections   int
+	responseEncoders map[string]ResponseEncoder
+	validator        *validator.Validate
+	translators      []ut.Translator
+
+	methodDefaultStatus      map[string]int
+	httpServerConfigurers    []func(*http.Server)
+	methodNotAllowed         bool
+	methodNotAllowedHandlers []gin.HandlerFunc
+	noRoute                  bool
+	noRouteHandlers          []gin.HandlerFunc
+	envPrefix                string
+	websocketUpgrader        *websocket.Upgrader
+	routeMetadata            map[string]map[string]string
+	addr                     string
+	recoveryStack            bool
+	ginMode                  string
+	ginEngineRecovery        bool
+	listener                 net.Listener
+	allowDuplicateMiddleware bool
+	emptyResponseStatus      int
+	contextInitializers      []gin.HandlerFunc
+	groups                   []*Group
+	basePath                 string
+	h2c                      bool
+	trailingSlashRedirect    *bool
+	fixedPathRedirect        *bool
+	groupResponders          map[string]*groupResponders
 
 	casualResponseErrorHandler func(err error, opts ...casual.HttpResponseParamsCb) (int, interface{})
 	casualResponseHandler      func(data any, opts ...casual.HttpResponseParamsCb) (int, interface{})
+	casualListResponseHandler  func(data any, opts ...casual.HttpResponseParamsCb) (int, interface{})
 }
 
 type ParamsCb func(*params) error
@@ -27,6 +79,8 @@ func WithLogger(log Logger) ParamsCb {
 	}
 }
 
+// WithGinEngine makes the engine register its routes on r instead of a Gin engine it creates itself.
+// See WithGinEngineRecovery for panics of an engine without middlewares and WithGinMode for the Gin mode.
 func WithGinEngine(r *gin.Engine) ParamsCb {
 	return func(params *params) error {
 		params.gin = r
@@ -35,6 +89,8 @@ func WithGinEngine(r *gin.Engine) ParamsCb {
 	}
 }
 
+// WithRootMiddlewares applies the middlewares declared in the given handlers to every route, before the
+// middlewares of its groups and its own.
 func WithRootMiddlewares(middlewares ...*Handler) ParamsCb {
 	return func(params *params) error {
 		params.rootMiddlewares = middlewares
@@ -43,6 +99,57 @@ func WithRootMiddlewares(middlewares ...*Handler) ParamsCb {
 	}
 }
 
+// WithContextInitializer runs init as the first handler of every route, before the root middlewares, e.g. to
+// set a tenant derived from the host on the context. It is a lighter alternative to a root middleware for
+// trivial context setup: init does not call Next, and it can abort the request. Initializers run in the order
+// they were provided.
+func WithContextInitializer(init func(*gin.Context)) ParamsCb {
+	return func(params *params) error {
+		if init == nil {
+			return fmt.Errorf("context initializer is nil")
+		}
+
+		params.contextInitializers = append(params.contextInitializers, init)
+
+		return nil
+	}
+}
+
+// WithGroups registers groups created with NewGroup. They are merged with the groups declared by the
+// handlers, replacing a declared group with the same name.
+func WithGroups(groups ...*Group) ParamsCb {
+	return func(params *params) error {
+		params.groups = append(params.groups, groups...)
+
+		return nil
+	}
+}
+
+// WithBasePath mounts every route under prefix, e.g. "/service-a" behind a gateway that does not strip it.
+// The prefix is prepended to the resolved route paths, after their group prefixes, so it is part of the paths
+// listed by Engine.Routes and of the route reported by gin.Context.FullPath. An empty prefix or "/" does nothing.
+func WithBasePath(prefix string) ParamsCb {
+	return func(params *params) error {
+		params.basePath = ""
+		if strings.Trim(prefix, "/") != "" {
+			params.basePath = prefix
+		}
+
+		return nil
+	}
+}
+
+// WithAllowDuplicateMiddleware controls whether a middleware declared on several levels of a route,
+// e.g. on its group and on the route itself, runs once per declaration. By default it runs once, at its
+// earliest position in the stack.
+func WithAllowDuplicateMiddleware(allow bool) ParamsCb {
+	return func(params *params) error {
+		params.allowDuplicateMiddleware = allow
+
+		return nil
+	}
+}
+
 func WithShutdownTimeout(timeout time.Duration) ParamsCb {
 	return func(params *params) error {
 		params.shutdownTimeout = timeout
@@ -51,6 +158,29 @@ func WithShutdownTimeout(timeout time.Duration) ParamsCb {
 	}
 }
 
+// WithPreShutdownDelay keeps the servers serving for the given delay after a termination signal is received
+// and before the graceful shutdown starts, giving load balancers time to deregister the instance.
+// The delay does not count towards the shutdown timeout.
+func WithPreShutdownDelay(delay time.Duration) ParamsCb {
+	return func(params *params) error {
+		params.preShutdownDelay = delay
+
+		return nil
+	}
+}
+
+// WithShutdownState makes the engine mark the given state as shutting down as soon as a termination signal
+// is received, so a health handler created with NewHealthHandler for the same state reports not ready.
+func WithShutdownState(state *ShutdownState) ParamsCb {
+	return func(params *params) error {
+		params.shutdownState = state
+
+		return nil
+	}
+}
+
+// WithTaskTracker sets the TaskTracker that counts in-flight requests and is drained on shutdown.
+// Without a tracker argument, or without this option, a tracker created by NewActiveTaskTracker is used.
 func WithTaskTracker(tracker ...TaskTracker) ParamsCb {
 	return func(params *params) error {
 		if len(tracker) == 0 {
@@ -61,3 +191,400 @@ func WithTaskTracker(tracker ...TaskTracker) ParamsCb {
 		return nil
 	}
 }
+
+// WithUnixSocket makes Run listen on a Unix domain socket at the given path instead of the TCP address.
+// A stale socket file left at the path, refusing connections, is removed on start, while a socket another process
+// still serves makes Run fail with ErrSocketInUse. The file is removed again on shutdown.
+// The socket file is created with the permissions of the process umask; restrict access to it either
+// through the umask or by placing the socket in a directory with the desired permissions.
+func WithUnixSocket(path string) ParamsCb {
+	return func(params *params) error {
+		params.unixSocket = path
+
+		return nil
+	}
+}
+
+// WithListener makes Run serve the given pre-bound listener instead of binding an address, e.g. a listener on
+// "127.0.0.1:0" whose port is known before the server starts. The address passed to Run and WithUnixSocket are
+// ignored. The listener is closed when the server shuts down.
+func WithListener(ln net.Listener) ParamsCb {
+	return func(params *params) error {
+		if ln == nil {
+			return ErrListenerNotSet
+		}
+
+		params.listener = ln
+
+		return nil
+	}
+}
+
+// WithTLS makes Run serve HTTPS with the given PEM encoded certificate and private key files.
+// Both files must exist, otherwise New fails. Graceful shutdown and request tracking work as for HTTP.
+func WithTLS(certFile, keyFile string) ParamsCb {
+	return func(params *params) error {
+		for _, file := range []string{certFile, keyFile} {
+			if _, err := os.Stat(file); err != nil {
+				return fmt.Errorf("failed to access tls file: %w", err)
+			}
+		}
+
+		params.tlsCertFile = certFile
+		params.tlsKeyFile = keyFile
+
+		return nil
+	}
+}
+
+// WithTLSConfig makes Run serve HTTPS with the given TLS configuration, which must provide the certificates
+// (e.g. via `Certificates` or `GetCertificate`). It can be combined with WithTLS to customize the configuration
+// used with the certificate files.
+func WithTLSConfig(config *tls.Config) ParamsCb {
+	return func(params *params) error {
+		if config == nil {
+			return ErrTLSConfigNotSet
+		}
+
+		params.tlsConfig = config
+
+		return nil
+	}
+}
+
+// WithValidator makes request binding validate with v, so custom validations and struct-level validations
+// registered on it apply to casual requests. Gin binds with the `binding` tag, so v usually needs
+// `v.SetTagName("binding")`. The validator only applies to the casual routes of this engine: Gin's process-wide
+// binding validator, used by engines without WithValidator and by ctx.ShouldBind calls, is left untouched.
+//
+// Validation error details are rendered with the translations registered on v for the given translators,
+// choosing the translator matching the response language or the first one. Tags without a registered
+// translation keep the built-in messages.
+//
+// **Example:**
+// ```go
+//
+//	v := validator.New()
+//	v.SetTagName("binding")
+//	_ = v.RegisterValidation("notblank", validators.NotBlank)
+//
+//	trans, _ := ut.New(en.New()).GetTranslator("en")
+//	_ = v.RegisterTranslation("notblank", trans, func(ut ut.Translator) error {
+//	    return ut.Add("notblank", "{0} must not be blank", true)
+//	}, func(ut ut.Translator, fe validator.FieldError) string {
+//	    msg, _ := ut.T("notblank", fe.Field())
+//	    return msg
+//	})
+//
+//	engine, _ := httpbara.New(handlers, httpbara.WithValidator(v, trans))
+//
+// ```
+func WithValidator(v *validator.Validate, translators ...ut.Translator) ParamsCb {
+	return func(params *params) error {
+		params.validator = v
+		params.translators = translators
+
+		return nil
+	}
+}
+
+// WithServers registers additional servers that serve the engine routes next to the regular listeners.
+// They are started by Run and RunMulti and shut down together with them.
+func WithServers(servers ...Server) ParamsCb {
+	return func(params *params) error {
+		params.servers = append(params.servers, servers...)
+
+		return nil
+	}
+}
+
+// WithHandlerWrapper registers a function that wraps the http.Handler served by every listener and
+// additional server, e.g. to set protocol specific headers before Gin handles the request.
+func WithHandlerWrapper(wrapper func(http.Handler) http.Handler) ParamsCb {
+	return func(params *params) error {
+		params.handlerWrappers = append(params.handlerWrappers, wrapper)
+
+		return nil
+	}
+}
+
+// WithHTTPServer registers a function that tunes every *http.Server created by Run and RunMulti before it
+// starts serving, e.g. to set `ReadHeaderTimeout`, `ReadTimeout`, `WriteTimeout`, `IdleTimeout` or `MaxHeaderBytes`
+// to protect against slow clients. The `Handler` of the server is always replaced by the engine handler
+// afterwards, so use WithHandlerWrapper to wrap it instead. Servers registered with WithServers are not affected.
+//
+// **Example:**
+// ```go
+//
+//	engine, _ := httpbara.New(handlers, httpbara.WithHTTPServer(func(srv *http.Server) {
+//	    srv.ReadHeaderTimeout = 5 * time.Second
+//	    srv.IdleTimeout = time.Minute
+//	}))
+//
+// ```
+func WithHTTPServer(configure func(srv *http.Server)) ParamsCb {
+	return func(params *params) error {
+		params.httpServerConfigurers = append(params.httpServerConfigurers, configure)
+
+		return nil
+	}
+}
+
+// WithMethodNotAllowedHandler makes requests to a registered path with a method it is not registered for
+// respond with 405 and an `Allow` header listing the methods registered for the path, instead of 404.
+// Without handlers, ErrMethodNotAllowed is rendered with the casual error responder; otherwise the given
+// handlers are called, with the `Allow` header already set. Routes registered with the `ANY` method match
+// every method and therefore never respond with 405.
+func WithMethodNotAllowedHandler(handlers ...gin.HandlerFunc) ParamsCb {
+	return func(params *params) error {
+		params.methodNotAllowed = true
+		params.methodNotAllowedHandlers = handlers
+
+		return nil
+	}
+}
+
+// WithNoMethodHandler is WithMethodNotAllowedHandler under the name of the Gin hook it configures (`NoMethod`).
+func WithNoMethodHandler(handlers ...gin.HandlerFunc) ParamsCb {
+	return WithMethodNotAllowedHandler(handlers...)
+}
+
+// WithNoRouteHandler makes requests that match no route respond through the given handlers instead of
+// Gin's plain text 404. Without handlers, casual.ErrNotFound is rendered with the casual error responder,
+// so unknown paths get the same error body as the routes.
+func WithNoRouteHandler(handlers ...gin.HandlerFunc) ParamsCb {
+	return func(params *params) error {
+		params.noRoute = true
+		params.noRouteHandlers = handlers
+
+		return nil
+	}
+}
+
+// WithRecoverHandler replaces the handler called by the recovery middleware of the base Gin engine when
+// a handler panics. It lets the recovered value be inspected and mapped to a specific response.
+// The handler is not used when a custom Gin engine is provided via WithGinEngine, unless WithGinEngineRecovery is.
+func WithRecoverHandler(handler RecoverHandler) ParamsCb {
+	return WithRecovery(handler)
+}
+
+// WithRecovery configures the recovery middleware of the base Gin engine. Panics are logged via the configured
+// Logger and passed to handler. A nil handler keeps the default, which renders casual.ErrInternalServerError
+// (or the casual.HttpError the handler panicked with) as a casual error envelope encoded per content negotiation.
+// The middleware is not used when a custom Gin engine is provided via WithGinEngine.
+//
+// Example:
+//
+//	httpbara.New(handlers, httpbara.WithRecovery(nil, httpbara.WithRecoveryStack()))
+func WithRecovery(handler RecoverHandler, opts ...RecoveryOpt) ParamsCb {
+	return func(params *params) error {
+		if handler != nil {
+			params.recoverHandler = handler
+		}
+
+		for _, opt := range opts {
+			opt(params)
+		}
+
+		return nil
+	}
+}
+
+// WithListEnvelope makes casual handlers that return a slice (e.g. `([]Product, error)`) respond with
+// the casual.HttpListResponse envelope (`items`, `total`, `cursor`) instead of the default casual.HttpResponse
+// envelope (`data` with `meta.total`). Handlers returning anything else are not affected.
+func WithListEnvelope() ParamsCb {
+	return func(params *params) error {
+		params.casualListResponseHandler = defaultCasualListResponder
+
+		return nil
+	}
+}
+
+// WithTotalCountHeader makes casual handlers that return a slice set the `X-Total-Count` header to the total
+// reported in the response meta (the slice length unless the value provides its own `total` via `Meta()`),
+// as expected by JSON-server compatible clients. The response body is not affected.
+func WithTotalCountHeader() ParamsCb {
+	return func(params *params) error {
+		params.totalCountHeader = true
+
+		return nil
+	}
+}
+
+// WithBareResponses makes casual handlers respond with the returned data itself (casual.HttpBareResponse)
+// instead of the casual.HttpResponse envelope. The status code is kept, and the meta is sent in headers:
+// `X-Total-Count` for slices, `X-Next-Cursor` for the cursor and `X-Meta-<Key>` for any other meta entry.
+// Errors keep the error envelope unless WithBareErrors is used as well.
+func WithBareResponses() ParamsCb {
+	return func(params *params) error {
+		params.casualResponseHandler = func(data any, opts ...casual.HttpResponseParamsCb) (int, interface{}) {
+			return casual.NewHTTPBareResponse(data, opts...)
+		}
+		params.casualListResponseHandler = nil
+
+		return nil
+	}
+}
+
+// WithBareErrors makes casual errors respond with the casual.HttpError itself (`code`, `message`, `details`)
+// instead of the casual.HttpErrorResponse envelope. Headers carried by the error are still set.
+func WithBareErrors() ParamsCb {
+	return func(params *params) error {
+		params.casualResponseErrorHandler = func(err error, opts ...casual.HttpResponseParamsCb) (int, interface{}) {
+			return casual.NewHttpBareErrorResponse(err, opts...)
+		}
+
+		return nil
+	}
+}
+
+// WithMethodDefaultStatus sets the status code of successful casual responses per HTTP method,
+// e.g. `map[string]int{"POST": http.StatusCreated, "DELETE": http.StatusNoContent}`. Methods missing
+// from the map keep responding with 200, and a `StatusCode()` method on the returned value still takes precedence.
+func WithMethodDefaultStatus(statuses map[string]int) ParamsCb {
+	return func(params *params) error {
+		if params.methodDefaultStatus == nil {
+			params.methodDefaultStatus = make(map[string]int, len(statuses))
+		}
+
+		for method, code := range statuses {
+			params.methodDefaultStatus[strings.ToUpper(method)] = code
+		}
+
+		return nil
+	}
+}
+
+// WithEmptyResponseStatus sets the status code of empty casual responses: a nil error returned by an error-only
+// handler, or a nil pointer returned with a nil error by a data handler. With http.StatusNoContent (the default)
+// they respond with 204 and no body. With http.StatusOK an error-only handler responds with an empty 200 and a data
+// handler with an envelope whose data is null. Only these two codes are accepted. A success status set for the route
+// (the `status` tag, WithMethodDefaultStatus or a `StatusCode()` method) takes precedence.
+func WithEmptyResponseStatus(code int) ParamsCb {
+	return func(params *params) error {
+		if code != http.StatusOK && code != http.StatusNoContent {
+			return fmt.Errorf("invalid empty response status %d: must be 200 or 204", code)
+		}
+
+		params.emptyResponseStatus = code
+
+		return nil
+	}
+}
+
+// WithServerTiming adds the `Server-Timing` response header to every route, reporting the time spent
+// handling the request as the `total` metric. Handlers and middlewares can report additional metrics
+// with AddServerTiming.
+func WithServerTiming() ParamsCb {
+	return func(params *params) error {
+		params.serverTiming = true
+
+		return nil
+	}
+}
+
+// WithDisallowUnknownFields makes casual handlers reject JSON request bodies containing fields that the request
+// struct does not declare. Such requests are answered with 400 and an error detail naming the unknown field.
+func WithDisallowUnknownFields() ParamsCb {
+	return func(params *params) error {
+		params.strictJSON = true
+
+		return nil
+	}
+}
+
+// WithTrailingSlashTrimming registers every route without trailing slashes, so `route:"GET /products/"`
+// and `route:"GET /products"` (or a group path ending with a slash) register the same path. The root path "/" is kept.
+// Requests to the slashed form are still redirected by Gin when `RedirectTrailingSlash` is enabled.
+func WithTrailingSlashTrimming() ParamsCb {
+	return func(params *params) error {
+		params.trimSlashes = true
+
+		return nil
+	}
+}
+
+// WithTrailingSlashRedirect sets `RedirectTrailingSlash` of the Gin engine, including one provided via WithGinEngine.
+// Gin enables it by default and redirects a request to the route with or without the trailing slash, with 301 for GET
+// and 307 for other methods. Disable it when a proxy rewrites paths in a way the redirect would break.
+func WithTrailingSlashRedirect(enabled bool) ParamsCb {
+	return func(params *params) error {
+		params.trailingSlashRedirect = &enabled
+
+		return nil
+	}
+}
+
+// WithFixedPathRedirect sets `RedirectFixedPath` of the Gin engine, including one provided via WithGinEngine.
+// When enabled, Gin redirects requests without a matching route to the cleaned, case-insensitively matched path.
+func WithFixedPathRedirect(enabled bool) ParamsCb {
+	return func(params *params) error {
+		params.fixedPathRedirect = &enabled
+
+		return nil
+	}
+}
+
+// WithPathTransformer rewrites the full path of every route, including its group prefixes, before it is
+// registered, e.g. to enforce lowercase or kebab-case paths centrally. Path parameters (`:id`, `*path`)
+// are part of the path passed to transformer, which must keep them intact. Routes reports the transformed paths.
+func WithPathTransformer(transformer func(path string) string) ParamsCb {
+	return func(params *params) error {
+		params.pathTransformer = transformer
+
+		return nil
+	}
+}
+
+// WithMaxConnections limits every listener to n simultaneously open connections. Beyond the limit new
+// connections are not accepted and wait in the operating system backlog until a connection is closed.
+// The limit applies per listener and counts connections, not requests: keep-alive connections hold a slot
+// while idle. It acts before any middleware, so waiting connections are neither counted by the task tracker
+// nor rejected by it during shutdown. Servers registered with WithServers are not limited.
+func WithMaxConnections(n int) ParamsCb {
+	return func(params *params) error {
+		params.maxConnections = n
+
+		return nil
+	}
+}
+
+// WithResponseEncoders registers response encoders by media type, negotiated against the Accept header
+// of the request for casual responses. JSON and XML encoders are registered by default and can be overridden.
+//
+// **Example:**
+// ```go
+//
+//	httpbara.WithResponseEncoders(map[string]httpbara.ResponseEncoder{
+//	    "application/yaml": func(ctx *gin.Context, code int, obj any) { ctx.YAML(code, obj) },
+//	})
+//
+// ```
+func WithResponseEncoders(encoders map[string]ResponseEncoder) ParamsCb {
+	return func(params *params) error {
+		if params.responseEncoders == nil {
+			params.responseEncoders = defaultResponseEncoders()
+		}
+
+		for mediaType, encoder := range encoders {
+			params.responseEncoders[strings.ToLower(mediaType)] = encoder
+		}
+
+		return nil
+	}
+}
+
+// WithOptions combines several options into one, which lets integration packages expose
+// a single option that configures multiple parts of the engine.
+func WithOptions(opts ...ParamsCb) ParamsCb {
+	return func(params *params) error {
+		for _, opt := range opts {
+			if err := opt(params); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
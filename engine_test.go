@@ -0,0 +1,60 @@
+package httpbara
+
+import "testing"
+
+func TestJoinPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		elems []string
+		want  string
+	}{
+		{name: "nothing", want: "/"},
+		{name: "empty elements", elems: []string{"", ""}, want: "/"},
+		{name: "root group and root route", elems: []string{"/", "/"}, want: "/"},
+		{name: "root group", elems: []string{"/", "/foo"}, want: "/foo"},
+		{name: "empty group", elems: []string{"", "/foo"}, want: "/foo"},
+		{name: "group and root route", elems: []string{"/api", "/"}, want: "/api/"},
+		{name: "group and empty route", elems: []string{"/api", ""}, want: "/api"},
+		{name: "slashes on both sides", elems: []string{"/api/", "/foo"}, want: "/api/foo"},
+		{name: "no slashes", elems: []string{"api", "foo"}, want: "/api/foo"},
+		{name: "duplicate slashes", elems: []string{"//api//", "//v1", "foo//bar"}, want: "/api/v1/foo/bar"},
+		{name: "trailing slash kept", elems: []string{"/api", "/foo/"}, want: "/api/foo/"},
+		{name: "path parameters", elems: []string{"/tenants/:tenant/", "/users/*path"}, want: "/tenants/:tenant/users/*path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joinPath(tt.elems...); got != tt.want {
+				t.Fatalf("joinPath(%q) = %q, want %q", tt.elems, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinGroupPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []string
+		path   string
+		want   string
+	}{
+		{name: "no group", path: "/foo", want: "/foo"},
+		{name: "root group", groups: []string{"/"}, path: "/foo", want: "/foo"},
+		{name: "empty group", groups: []string{""}, path: "/foo", want: "/foo"},
+		{name: "nested groups", groups: []string{"/api/", "/v1/"}, path: "/foo", want: "/api/v1/foo"},
+		{name: "nested root groups", groups: []string{"/", "/"}, path: "/", want: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := make([]*Group, 0, len(tt.groups))
+			for _, path := range tt.groups {
+				chain = append(chain, &Group{Path: path})
+			}
+
+			if got := joinGroupPath(chain, tt.path); got != tt.want {
+				t.Fatalf("joinGroupPath(%q, %q) = %q, want %q", tt.groups, tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package httpbara
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// WithEnvConfig configures the engine from environment variables named with the given prefix,
+// so deployments can be tuned without code changes. The variables are applied before every other option,
+// so options passed to New explicitly win over the environment. Unset or empty variables are ignored,
+// and a malformed value makes New fail.
+//
+// Recognized variables, shown for the prefix "APP":
+// - `APP_ADDR` (string): The address Run listens on when called with an empty address, e.g. "127.0.0.1:8080".
+// - `APP_PORT` (int): The port Run listens on when called with an empty address and `APP_ADDR` is not set.
+// - `APP_SHUTDOWN_TIMEOUT` (duration, e.g. "10s"): See WithShutdownTimeout.
+// - `APP_PRE_SHUTDOWN_DELAY` (duration): See WithPreShutdownDelay.
+// - `APP_MAX_CONNECTIONS` (int): See WithMaxConnections.
+// - `APP_UNIX_SOCKET` (string): See WithUnixSocket.
+// - `APP_TLS_CERT_FILE` and `APP_TLS_KEY_FILE` (string): See WithTLS. Both must be set.
+// - `APP_GIN_MODE` ("debug", "release" or "test"): See WithGinMode.
+//
+// **Example:**
+// ```go
+//
+//	engine, _ := httpbara.New(handlers, httpbara.WithEnvConfig("APP"))
+//	err := engine.Run("") // listens on APP_ADDR or APP_PORT
+//
+// ```
+func WithEnvConfig(prefix string) ParamsCb {
+	return func(params *params) error {
+		params.envPrefix = prefix
+
+		return nil
+	}
+}
+
+// envConfig reads the variables recognized by WithEnvConfig and returns them as options.
+func envConfig(prefix string) ([]ParamsCb, error) {
+	lookup := func(name string) (string, bool) {
+		value := os.Getenv(prefix + "_" + name)
+
+		return value, value != ""
+	}
+
+	opts := make([]ParamsCb, 0)
+
+	if addr, ok := lookup("ADDR"); ok {
+		opts = append(opts, withAddr(addr))
+	} else if port, ok := lookup("PORT"); ok {
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return nil, fmt.Errorf("invalid %s_PORT: %w", prefix, err)
+		}
+
+		opts = append(opts, withAddr(":"+port))
+	}
+
+	for name, opt := range map[string]func(time.Duration) ParamsCb{
+		"SHUTDOWN_TIMEOUT":   WithShutdownTimeout,
+		"PRE_SHUTDOWN_DELAY": WithPreShutdownDelay,
+	} {
+		if value, ok := lookup(name); ok {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s_%s: %w", prefix, name, err)
+			}
+
+			opts = append(opts, opt(duration))
+		}
+	}
+
+	if value, ok := lookup("MAX_CONNECTIONS"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s_MAX_CONNECTIONS: %w", prefix, err)
+		}
+
+		opts = append(opts, WithMaxConnections(n))
+	}
+
+	if path, ok := lookup("UNIX_SOCKET"); ok {
+		opts = append(opts, WithUnixSocket(path))
+	}
+
+	certFile, certOk := lookup("TLS_CERT_FILE")
+	keyFile, keyOk := lookup("TLS_KEY_FILE")
+	if certOk != keyOk {
+		return nil, fmt.Errorf("%s_TLS_CERT_FILE and %s_TLS_KEY_FILE must be set together", prefix, prefix)
+	} else if certOk {
+		opts = append(opts, WithTLS(certFile, keyFile))
+	}
+
+	if mode, ok := lookup("GIN_MODE"); ok {
+		if !validGinMode(mode) {
+			return nil, fmt.Errorf("invalid %s_GIN_MODE: %q", prefix, mode)
+		}
+
+		opts = append(opts, WithGinMode(mode))
+	}
+
+	return opts, nil
+}
+
+// withAddr sets the address Run listens on when it is called with an empty address.
+func withAddr(addr string) ParamsCb {
+	return func(params *params) error {
+		params.addr = addr
+
+		return nil
+	}
+}
@@ -0,0 +1,95 @@
+package httpbara_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gopybara/httpbara"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type errorHeadersHandlerDescriber struct {
+	Fail httpbara.Route `route:"GET /fail"`
+}
+
+// errorHeadersHandler fails with the error set by the test.
+type errorHeadersHandler struct {
+	errorHeadersHandlerDescriber
+
+	err error
+}
+
+func (h *errorHeadersHandler) Fail(ctx context.Context) error {
+	return h.err
+}
+
+func TestErrorResponseHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		accept  string
+		status  int
+		headers map[string]string
+	}{
+		{
+			name:    "unauthorized",
+			err:     casual.NewHTTPErrorWithHeaders(http.StatusUnauthorized, "unauthorized", map[string]string{"WWW-Authenticate": `Bearer realm="api"`}),
+			status:  http.StatusUnauthorized,
+			headers: map[string]string{"WWW-Authenticate": `Bearer realm="api"`},
+		},
+		{
+			name:    "rate limited",
+			err:     casual.NewHTTPErrorWithHeaders(http.StatusTooManyRequests, "slow down", map[string]string{"Retry-After": "30", "X-RateLimit-Limit": "100"}),
+			status:  http.StatusTooManyRequests,
+			headers: map[string]string{"Retry-After": "30", "X-RateLimit-Limit": "100"},
+		},
+		{
+			name:    "wrapped",
+			err:     fmt.Errorf("checking quota: %w", casual.NewHTTPErrorWithHeaders(http.StatusTooManyRequests, "slow down", map[string]string{"Retry-After": "30"})),
+			status:  http.StatusTooManyRequests,
+			headers: map[string]string{"Retry-After": "30"},
+		},
+		{
+			name:    "xml response",
+			err:     casual.NewHTTPErrorWithHeaders(http.StatusTooManyRequests, "slow down", map[string]string{"Retry-After": "30"}),
+			accept:  "application/xml",
+			status:  http.StatusTooManyRequests,
+			headers: map[string]string{"Retry-After": "30"},
+		},
+		{
+			name:    "without headers",
+			err:     errors.New("boom"),
+			status:  http.StatusInternalServerError,
+			headers: map[string]string{"Retry-After": "", "WWW-Authenticate": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &errorHeadersHandler{err: tt.err})})
+
+			headers := map[string]string{}
+			if tt.accept != "" {
+				headers["Accept"] = tt.accept
+			}
+
+			rec := serve(h, http.MethodGet, "/fail", nil, headers)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			for key, value := range tt.headers {
+				if got := rec.Header().Get(key); got != value {
+					t.Fatalf("%s = %q, want %q", key, got, value)
+				}
+
+				if value != "" && strings.Contains(rec.Body.String(), value) {
+					t.Fatalf("body %q carries the header value %q", rec.Body.String(), value)
+				}
+			}
+		})
+	}
+}
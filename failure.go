@@ -0,0 +1,19 @@
+package httpbara
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+)
+
+// failureHandler returns the outermost handler of every route, which renders the error passed to casual.Fail
+// by a middleware or handler with the casual error responder, unless a response was already written.
+func (c *core) failureHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if err := casual.Failure(ctx); err != nil && !ctx.Writer.Written() {
+			rcb := c.getResponseCallback(ctx)
+			rcb(c.casualResponseErrorHandler(err, languageParams(ctx)...))
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package httpbara
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"os"
+)
+
+// WithGinMode sets the process-wide Gin mode ("debug", "release" or "test") via gin.SetMode before the engine
+// is created. Without this option the engine switches Gin to release mode, unless the `GIN_MODE` environment
+// variable already chose a mode.
+//
+// The mode is set even when a custom engine is provided via WithGinEngine, but that engine already exists by then:
+// it only affects what Gin does afterwards, such as printing the registered routes in debug mode.
+func WithGinMode(mode string) ParamsCb {
+	return func(params *params) error {
+		if !validGinMode(mode) {
+			return fmt.Errorf("invalid gin mode: %q", mode)
+		}
+
+		params.ginMode = mode
+
+		return nil
+	}
+}
+
+// WithGinEngineRecovery attaches the recovery middleware configured via WithRecovery to the engine provided via
+// WithGinEngine when that engine has no middleware yet. Engines that already have middlewares are left untouched,
+// as they are expected to bring their own recovery.
+//
+// Without this option, New only warns about a custom engine without middlewares, since panics in its handlers are
+// not recovered.
+func WithGinEngineRecovery() ParamsCb {
+	return func(params *params) error {
+		params.ginEngineRecovery = true
+
+		return nil
+	}
+}
+
+func validGinMode(mode string) bool {
+	switch mode {
+	case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+		return true
+	default:
+		return false
+	}
+}
+
+// applyGinMode sets the Gin mode configured via WithGinMode, or release mode when neither the option nor
+// the `GIN_MODE` environment variable chose one.
+func (c *core) applyGinMode() {
+	if c.ginMode != "" {
+		gin.SetMode(c.ginMode)
+	} else if os.Getenv(gin.EnvGinMode) == "" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+}
+
+// applyGinEngineRecovery attaches the recovery middleware to a custom engine without middlewares
+// when WithGinEngineRecovery was provided, and warns about it otherwise.
+func (c *core) applyGinEngineRecovery() {
+	if len(c.gin.Handlers) > 0 {
+		return
+	}
+
+	if c.ginEngineRecovery {
+		c.gin.Use(c.recovery())
+		return
+	}
+
+	c.warn("gin engine has no middlewares, so panics are not recovered; see WithGinEngineRecovery")
+}
@@ -0,0 +1,97 @@
+package httpbara
+
+import (
+	"github.com/gopybara/httpbara/casual"
+)
+
+// groupResponders holds the responders registered for a group via WithGroupResponders.
+type groupResponders struct {
+	success func(data any, opts ...casual.HttpResponseParamsCb) (int, interface{})
+	failure func(err error, opts ...casual.HttpResponseParamsCb) (int, interface{})
+}
+
+// WithGroupResponders overrides how the casual routes of a group respond, e.g. to keep a legacy envelope for
+// the routes of an old API version. success renders the data returned by the handlers and failure the returned
+// errors, like casual.NewHTTPResponse and casual.NewHttpErrorResponse do by default; a nil one keeps the engine
+// responder. The override applies to the routes of the group and of its nested groups, unless one of them has
+// its own. Errors answered before the handler runs, such as binding failures of the casual route, use it too,
+// while the middlewares of the engine (timeouts, constraints, recovery) keep the engine responder.
+//
+// **Example:**
+// ```go
+//
+//	engine, _ := httpbara.New(handlers,
+//	    httpbara.WithGroupResponders("legacy", legacyResponder, legacyErrorResponder),
+//	)
+//
+// ```
+func WithGroupResponders(group string, success func(data any, opts ...casual.HttpResponseParamsCb) (int, interface{}), failure func(err error, opts ...casual.HttpResponseParamsCb) (int, interface{})) ParamsCb {
+	return func(params *params) error {
+		if params.groupResponders == nil {
+			params.groupResponders = make(map[string]*groupResponders)
+		}
+
+		params.groupResponders[parseGroupReference(group)] = &groupResponders{
+			success: success,
+			failure: failure,
+		}
+
+		return nil
+	}
+}
+
+// respondersOf returns the responders used by the casual routes of group: the ones registered via
+// WithGroupResponders for the group or its nearest ancestor, falling back to the engine responders.
+// The list responder is only used with the engine success responder.
+func (c *core) respondersOf(group string) (
+	success func(data any, opts ...casual.HttpResponseParamsCb) (int, interface{}),
+	list func(data any, opts ...casual.HttpResponseParamsCb) (int, interface{}),
+	errorResponder func(err error, opts ...casual.HttpResponseParamsCb) (int, interface{}),
+) {
+	success, list, errorResponder = c.casualResponseHandler, c.casualListResponseHandler, c.casualResponseErrorHandler
+
+	overrides := c.groupRespondersOf(group)
+	if overrides == nil {
+		return success, list, errorResponder
+	}
+
+	if overrides.success != nil {
+		success, list = overrides.success, nil
+	}
+
+	if overrides.failure != nil {
+		errorResponder = overrides.failure
+		if len(c.translators) > 0 {
+			errorResponder = func(err error, opts ...casual.HttpResponseParamsCb) (int, interface{}) {
+				return overrides.failure(err, append(opts, casual.WithTranslators(c.translators...))...)
+			}
+		}
+	}
+
+	return success, list, errorResponder
+}
+
+// groupRespondersOf returns the responders registered for group or its nearest ancestor, or nil.
+func (c *core) groupRespondersOf(group string) *groupResponders {
+	visited := make(map[string]struct{})
+
+	for group != "" {
+		if overrides, ok := c.groupResponders[group]; ok {
+			return overrides
+		}
+
+		if _, ok := visited[group]; ok {
+			return nil
+		}
+		visited[group] = struct{}{}
+
+		parent, ok := c.flatGroups[group]
+		if !ok {
+			return nil
+		}
+
+		group = parent.parent
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package httpbara_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"testing"
+)
+
+type groupRoutesHandlerDescriber struct {
+	Users httpbara.Route `route:"GET /users" group:"V1"`
+	Items httpbara.Route `route:"GET /items" group:" admin "`
+}
+
+// groupRoutesHandler declares routes referring to groups declared by groupsHandler.
+type groupRoutesHandler struct {
+	groupRoutesHandlerDescriber
+}
+
+func (h *groupRoutesHandler) Users(ctx *gin.Context) {
+	ctx.String(http.StatusOK, "users")
+}
+
+func (h *groupRoutesHandler) Items(ctx *gin.Context) {
+	ctx.String(http.StatusOK, "items")
+}
+
+type groupsHandlerDescriber struct {
+	Admin httpbara.Group `group:"/admin" parent:"V1"`
+	V1    httpbara.Group `group:"/v1" middlewares:"versioned"`
+}
+
+// groupsHandler declares a child group before its parent, with a middleware declared by groupMiddlewareHandler.
+type groupsHandler struct {
+	groupsHandlerDescriber
+}
+
+type groupMiddlewareHandlerDescriber struct {
+	Versioned httpbara.Middleware `middleware:"versioned"`
+}
+
+type groupMiddlewareHandler struct {
+	groupMiddlewareHandlerDescriber
+}
+
+func (h *groupMiddlewareHandler) Versioned(ctx *gin.Context) {
+	ctx.Header("X-Api-Version", "1")
+}
+
+func TestGroupResolutionOrder(t *testing.T) {
+	routes := &groupRoutesHandler{}
+	groups := &groupsHandler{}
+	middleware := &groupMiddlewareHandler{}
+
+	tests := []struct {
+		name       string
+		describers []any
+	}{
+		{name: "groups declared later", describers: []any{routes, groups, middleware}},
+		{name: "groups declared first", describers: []any{middleware, groups, routes}},
+		{name: "middleware declared last", describers: []any{groups, routes, middleware}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers := make([]*httpbara.Handler, 0, len(tt.describers))
+			for _, describer := range tt.describers {
+				handlers = append(handlers, mustHandler(t, describer))
+			}
+
+			engine, err := httpbara.New(handlers, httpbara.WithLogger(discardLogger{}))
+			if err != nil {
+				t.Fatalf("failed to create engine: %v", err)
+			}
+
+			if warnings := engine.Warnings(); len(warnings) != 0 {
+				t.Fatalf("warnings = %v, want none", warnings)
+			}
+
+			for path, body := range map[string]string{"/v1/users": "users", "/v1/admin/items": "items"} {
+				rec := serve(engine.AsHTTPHandler(), http.MethodGet, path, nil, nil)
+				if rec.Code != http.StatusOK || rec.Body.String() != body {
+					t.Fatalf("GET %s = %d %q, want %d %q", path, rec.Code, rec.Body.String(), http.StatusOK, body)
+				}
+
+				if version := rec.Header().Get("X-Api-Version"); version != "1" {
+					t.Fatalf("GET %s X-Api-Version = %q, want the group middleware to set 1", path, version)
+				}
+			}
+		})
+	}
+}
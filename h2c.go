@@ -0,0 +1,32 @@
+package httpbara
+
+import (
+	"fmt"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net/http"
+)
+
+// WithH2C makes the cleartext listeners of Run, RunMulti, Start and StartMulti serve HTTP/2 without TLS (h2c),
+// both with prior knowledge and through an `Upgrade: h2c` request, next to HTTP/1.1. This is meant for trusted
+// networks only, e.g. behind a load balancer terminating TLS: h2c offers no encryption nor authentication of the
+// peer. HTTP/2 connections are shut down gracefully together with the server. TLS listeners negotiate HTTP/2
+// as usual and are not affected.
+func WithH2C(enabled bool) ParamsCb {
+	return func(params *params) error {
+		params.h2c = enabled
+
+		return nil
+	}
+}
+
+// h2cHandler configures srv for HTTP/2 and returns handler wrapped to serve h2c requests. The HTTP/2 server
+// is registered with srv, so shutting srv down also drains the HTTP/2 connections.
+func h2cHandler(srv *http.Server, handler http.Handler) (http.Handler, error) {
+	h2s := &http2.Server{}
+	if err := http2.ConfigureServer(srv, h2s); err != nil {
+		return nil, fmt.Errorf("failed to configure h2c server: %w", err)
+	}
+
+	return h2c.NewHandler(handler, h2s), nil
+}
@@ -4,9 +4,28 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"net/http"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+)
+
+var (
+	// ErrGroupCycle is returned by New when groups reference each other as parents in a cycle.
+	ErrGroupCycle = errors.New("group parent cycle")
+
+	// ErrUnimplementedRoute is returned by AsHandler when route fields have no handler method with the same name.
+	ErrUnimplementedRoute = errors.New("route has no handler method")
+
+	// ErrInvalidStatusTag is returned by AsHandler when a `status` tag is not a 2xx status code
+	// or is set on a route without casual handler.
+	ErrInvalidStatusTag = errors.New("invalid status tag")
+
+	ginHandlerFuncType = reflect.TypeOf(gin.HandlerFunc(nil))
+
+	routeTagRegexp = regexp.MustCompile(`(?i)^([A-Z]{2,10}) (.*)$`)
 )
 
 const (
@@ -21,6 +40,24 @@ const (
 
 	// RouteTag is a struct tag key used to define the route's HTTP method and path.
 	RouteTag = "route"
+
+	// ParentTag is a struct tag key used to nest a group under another group by name.
+	ParentTag = "parent"
+
+	// ConstraintsTag is a struct tag key used to specify a comma-separated list of path parameter constraints.
+	ConstraintsTag = "constraints"
+
+	// TimeoutTag is a struct tag key used to specify a per-route timeout as a Go duration (e.g. "5s").
+	TimeoutTag = "timeout"
+
+	// MetaTag is a struct tag key used to attach comma-separated `key=value` metadata to a route.
+	MetaTag = "meta"
+
+	// StatusTag is a struct tag key used to set the success status code of a casual route (e.g. "201").
+	StatusTag = "status"
+
+	// ConsumesTag is a struct tag key used to restrict the request content types of a route (e.g. "application/json").
+	ConsumesTag = "consumes"
 )
 
 // Handler processes a given handler struct to extract and configure routes, groups, and middlewares.
@@ -29,7 +66,8 @@ const (
 // The workflow is as follows:
 // 1. Recursively scan the provided struct (including embedded and nested structs) for fields of type `Route`, `Group`, and `Middleware`.
 // 2. Extract corresponding tags (e.g. `route:"POST /checkout/apply"`) to determine routes, their HTTP methods, paths, and middleware associations.
-// 3. Match field names with struct methods (signature `func(*gin.Context)`) to create fully configured routes and middleware.
+// 3. Match field names with exported struct methods (signature `func(*gin.Context)`) to create fully configured routes and middleware.
+// Describer fields may be unexported, but the handler methods they refer to must be exported.
 // 4. Store all parsed routes, groups, and middleware for later registration in a Gin router.
 //
 // **Example:**
@@ -128,6 +166,25 @@ type Handler struct {
 
 	groups      []*Group
 	middlewares []*Middleware
+
+	// unimplementedRoutes are the names of route fields without a handler method,
+	// kept when AsHandler was called with WithAllowUnimplementedRoutes.
+	unimplementedRoutes []string
+}
+
+type handlerOpts struct {
+	allowUnimplementedRoutes bool
+}
+
+type HandlerOpt func(*handlerOpts)
+
+// WithAllowUnimplementedRoutes makes AsHandler skip route fields without a handler method instead of failing
+// with ErrUnimplementedRoute. The skipped routes are reported as warnings by New (see Engine.Warnings),
+// which helps when declaring routes ahead of their implementation.
+func WithAllowUnimplementedRoutes(allow bool) HandlerOpt {
+	return func(opts *handlerOpts) {
+		opts.allowUnimplementedRoutes = allow
+	}
 }
 
 // AsHandler creates a new Handler by analyzing the provided `handlerStruct`.
@@ -142,11 +199,20 @@ type Handler struct {
 // // The handler now holds routes like GET /api/v3/products (with auth, logging middleware),
 // // and GET /api/v3/products/:id, ready to be registered in your Gin engine.
 // ```
-func AsHandler(handlerStruct interface{}) (*Handler, error) {
+//
+// Every route field needs an exported method with the same name and a Gin or casual handler signature.
+// Route fields without one make AsHandler fail with ErrUnimplementedRoute naming all of them,
+// unless WithAllowUnimplementedRoutes is given.
+func AsHandler(handlerStruct interface{}, opts ...HandlerOpt) (*Handler, error) {
+	var ho handlerOpts
+	for _, opt := range opts {
+		opt(&ho)
+	}
+
 	handler := &Handler{}
 
-	ginHandlers, casualHandlers := handler.getAllGinHandlers(reflect.ValueOf(handlerStruct))
-	flatFields := handler.getAllReflectionFieldsRecursive(reflect.ValueOf(handlerStruct))
+	ginHandlers, casualHandlers, websocketHandlers, staticHandlers, middlewareFactories := handler.getAllGinHandlers(reflect.ValueOf(handlerStruct))
+	flatFields, funcFields := handler.getAllReflectionFieldsRecursive(reflect.ValueOf(handlerStruct))
 
 	err := handler.searchForGroups(flatFields)
 	if err != nil {
@@ -156,9 +222,9 @@ func AsHandler(handlerStruct interface{}) (*Handler, error) {
 		)
 	}
 
-	handler.searchForMiddlewares(flatFields, ginHandlers)
+	handler.searchForMiddlewares(flatFields, ginHandlers, middlewareFactories, funcFields)
 
-	err = handler.searchForRoutes(flatFields, ginHandlers, casualHandlers)
+	err = handler.searchForRoutes(flatFields, ginHandlers, casualHandlers, websocketHandlers, staticHandlers)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"failed to search for routes: %w",
@@ -166,6 +232,10 @@ func AsHandler(handlerStruct interface{}) (*Handler, error) {
 		)
 	}
 
+	if len(handler.unimplementedRoutes) > 0 && !ho.allowUnimplementedRoutes {
+		return nil, fmt.Errorf("%w: %s", ErrUnimplementedRoute, strings.Join(handler.unimplementedRoutes, ", "))
+	}
+
 	return handler, nil
 }
 
@@ -179,58 +249,175 @@ func AsHandler(handlerStruct interface{}) (*Handler, error) {
 // ListProducts Route `route:"GET /products" middlewares:"auth,logging" group:"v3"`
 // ```
 // This defines a GET route at `/api/v3/products` (because of group "v3"), with middleware "auth" and "logging".
-func (h *Handler) searchForRoutes(flatFields []reflect.StructField, foundHandlers map[string]gin.HandlerFunc, foundCasualHandlers map[string]*casualHandler) error {
-	typeOfRoute := reflect.TypeOf(Route{})
-	var err error
+//
+// An optional `timeout` tag (e.g. `timeout:"5s"`) bounds the request context of the route, and an optional
+// `meta` tag (e.g. `meta:"cache=public,scope=orders:read"`) attaches metadata read with RouteMetadata.
+// An optional `consumes` tag (e.g. `consumes:"application/json,multipart/form-data"`) answers requests with another
+// content type with 415 before binding; websocket and static routes reject it. Casual routes accept a `status` tag (e.g. `status:"201"`) setting their success status code, which
+// a `StatusCode()` method of the response still overrides.
+// Route fields without a handler method are collected in unimplementedRoutes.
+//
+// Routes declared with the `WS` method (e.g. `route:"WS /live"`) need a websocket handler method and are
+// registered as GET routes upgrading the connection, see WithWebsocketUpgrader. Routes declared with the
+// `STATIC` method (e.g. `route:"STATIC /assets"`) need a static handler method and serve files, see staticHandler.
+func (h *Handler) searchForRoutes(flatFields []reflect.StructField, foundHandlers map[string]gin.HandlerFunc, foundCasualHandlers map[string]*casualHandler, foundWebsocketHandlers map[string]websocketHandler, foundStaticHandlers map[string]reflect.Value) error {
 	routes := make([]*Route, 0)
 	casualRoutes := make([]*casualRoute, 0)
+	var unimplemented []string
 
 	for _, fieldType := range flatFields {
-		if fieldType.Type != typeOfRoute {
+		if !isMarker(routeMarkers, fieldType.Type) {
 			continue
 		}
 
-		if foundHandlers[fieldType.Name] != nil {
-			route := &Route{
-				handler:     foundHandlers[fieldType.Name],
-				middlewares: h.parseMiddlewaresTag(fieldType.Tag.Get(MiddlewaresTag)),
-				group:       fieldType.Tag.Get(GroupTag),
+		if fieldType.Tag.Get(StatusTag) != "" && foundCasualHandlers[fieldType.Name] == nil {
+			return fmt.Errorf("%w: %s has no casual handler", ErrInvalidStatusTag, fieldType.Name)
+		}
+
+		if foundStaticHandlers[fieldType.Name].IsValid() {
+			route, err := h.parseRouteField(fieldType, StaticMethod)
+			if err != nil {
+				return err
+			}
+
+			route.handler, err = staticHandler(fieldType.Name, foundStaticHandlers[fieldType.Name])
+			if err != nil {
+				return err
 			}
 
-			route.method, route.path, err = h.parseRouteTag(fieldType.Tag.Get(RouteTag))
+			routes = append(routes, route)
+		} else if foundWebsocketHandlers[fieldType.Name] != nil {
+			route, err := h.parseRouteField(fieldType, WebsocketMethod)
 			if err != nil {
-				return fmt.Errorf("failed to parse route tag: %w", err)
+				return err
 			}
 
+			route.websocket = foundWebsocketHandlers[fieldType.Name]
+
+			routes = append(routes, route)
+		} else if foundHandlers[fieldType.Name] != nil {
+			route, err := h.parseRouteField(fieldType, "")
+			if err != nil {
+				return err
+			}
+
+			route.handler = foundHandlers[fieldType.Name]
+
 			routes = append(routes, route)
 		} else if foundCasualHandlers[fieldType.Name] != nil {
-			route := &casualRoute{
-				handler:     foundCasualHandlers[fieldType.Name],
-				middlewares: h.parseMiddlewaresTag(fieldType.Tag.Get(MiddlewaresTag)),
-				group:       fieldType.Tag.Get(GroupTag),
+			if err := validateCasualRequest(foundCasualHandlers[fieldType.Name]); err != nil {
+				return err
+			}
+
+			if err := validateCasualResponse(foundCasualHandlers[fieldType.Name]); err != nil {
+				return err
+			}
+
+			route, err := h.parseRouteField(fieldType, "")
+			if err != nil {
+				return err
 			}
 
-			route.method, route.path, err = h.parseRouteTag(fieldType.Tag.Get(RouteTag))
+			status, err := h.parseStatusTag(fieldType.Tag.Get(StatusTag))
 			if err != nil {
-				return fmt.Errorf("failed to parse route tag: %w", err)
+				return fmt.Errorf("%w: %s: %w", ErrInvalidStatusTag, fieldType.Name, err)
 			}
 
-			casualRoutes = append(casualRoutes, route)
+			casualRoutes = append(casualRoutes, &casualRoute{
+				name:        route.name,
+				middlewares: route.middlewares,
+				group:       route.group,
+				method:      route.method,
+				path:        route.path,
+				handler:     foundCasualHandlers[fieldType.Name],
+				constraints: route.constraints,
+				consumes:    route.consumes,
+				metadata:    route.metadata,
+				status:      status,
+
+				timeout:        route.timeout,
+				invalidTimeout: route.invalidTimeout,
+			})
+		} else {
+			unimplemented = append(unimplemented, fieldType.Name)
 		}
 	}
 
 	h.routes = routes
 	h.casualRoutes = casualRoutes
+	h.unimplementedRoutes = unimplemented
 
 	return nil
 }
 
+// parseRouteField builds the route declared by field from its tags, leaving the handler to the caller.
+// kind is the method the route tag must declare for websocket and static handlers (WebsocketMethod or StaticMethod),
+// which are registered as GET routes, or empty for Gin and casual handlers, which must declare neither.
+// Websocket and static routes receive no request body, so they reject a `consumes` tag.
+func (h *Handler) parseRouteField(field reflect.StructField, kind string) (*Route, error) {
+	var err error
+
+	route := &Route{
+		name:        field.Name,
+		middlewares: parseMiddlewaresTag(field.Tag.Get(MiddlewaresTag)),
+		group:       parseGroupReference(field.Tag.Get(GroupTag)),
+	}
+
+	route.method, route.path, err = h.parseRouteTag(field.Tag.Get(RouteTag))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse route tag: %w", err)
+	}
+
+	switch kind {
+	case WebsocketMethod:
+		if !strings.EqualFold(route.method, WebsocketMethod) {
+			return nil, fmt.Errorf("%w: %s is declared as %s", ErrInvalidWebsocketRoute, field.Name, route.method)
+		}
+
+		route.method = http.MethodGet
+	case StaticMethod:
+		if !strings.EqualFold(route.method, StaticMethod) {
+			return nil, fmt.Errorf("%w: %s is declared as %s", ErrInvalidStaticRoute, field.Name, route.method)
+		}
+
+		route.method = http.MethodGet
+		route.path = joinPath(route.path, "*"+staticFilepathParam)
+	default:
+		if strings.EqualFold(route.method, WebsocketMethod) {
+			return nil, fmt.Errorf("%w: %s has no websocket handler signature", ErrInvalidWebsocketRoute, field.Name)
+		} else if strings.EqualFold(route.method, StaticMethod) {
+			return nil, fmt.Errorf("%w: %s has no static handler signature", ErrInvalidStaticRoute, field.Name)
+		}
+	}
+
+	route.consumes = h.parseConsumesTag(field.Tag.Get(ConsumesTag))
+	if kind != "" && len(route.consumes) > 0 {
+		return nil, fmt.Errorf("%w: %s is a %s route", ErrUnsupportedConsumesTag, field.Name, kind)
+	}
+
+	route.constraints, err = h.parseConstraintsTag(field.Tag.Get(ConstraintsTag))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse constraints tag: %w", err)
+	}
+
+	// Parameters of grouped routes may come from the group path, which New checks once it is resolved
+	if route.group == "" {
+		if err = checkConstraintParams(route.constraints, route.path); err != nil {
+			return nil, fmt.Errorf("%s: %w", field.Name, err)
+		}
+	}
+
+	route.timeout, route.invalidTimeout = h.parseTimeoutTag(field.Tag.Get(TimeoutTag))
+	route.metadata = h.parseMetaTag(field.Tag.Get(MetaTag))
+
+	return route, nil
+}
+
 // parseRouteTag parses a route tag which should be in the format: "METHOD /path".
 // For example: "POST /checkout/apply".
 // It returns the extracted HTTP method and path, or an error if the format is invalid.
 func (h *Handler) parseRouteTag(tag string) (method string, path string, err error) {
-	re := regexp.MustCompile(`(?i)^([A-Z]{3,10}) (.*)$`)
-	matches := re.FindStringSubmatch(tag)
+	matches := routeTagRegexp.FindStringSubmatch(tag)
 	if len(matches) != 3 {
 		return "", "", errors.New("invalid route tag")
 	}
@@ -238,6 +425,59 @@ func (h *Handler) parseRouteTag(tag string) (method string, path string, err err
 	return matches[1], matches[2], nil
 }
 
+// parseStatusTag parses a `status` tag as the success status code of a casual route, e.g. `status:"201"`.
+// An absent tag yields zero, codes outside of 2xx are rejected.
+func (h *Handler) parseStatusTag(tag string) (int, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return 0, nil
+	}
+
+	status, err := strconv.Atoi(tag)
+	if err != nil {
+		return 0, err
+	} else if status < 200 || status > 299 {
+		return 0, fmt.Errorf("%d is not a success status code", status)
+	}
+
+	return status, nil
+}
+
+// parseTimeoutTag parses a `timeout` tag as a Go duration, e.g. `timeout:"5s"`.
+// An absent tag yields no timeout. A malformed or non-positive duration also yields no timeout
+// and is returned as invalid, so the engine can warn about it when registering the route.
+func (h *Handler) parseTimeoutTag(tag string) (timeout time.Duration, invalid string) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return 0, ""
+	}
+
+	timeout, err := time.ParseDuration(tag)
+	if err != nil || timeout <= 0 {
+		return 0, tag
+	}
+
+	return timeout, ""
+}
+
+// parseMetaTag parses a `meta` tag of comma-separated `key=value` pairs, e.g. `meta:"cache=public,beta=true"`.
+// Keys and values are trimmed, and a key without a value maps to an empty string. An absent tag yields nil.
+func (h *Handler) parseMetaTag(tag string) map[string]string {
+	if strings.TrimSpace(tag) == "" {
+		return nil
+	}
+
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(pair, "=")
+		if key = strings.TrimSpace(key); key != "" {
+			metadata[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return metadata
+}
+
 // searchForMiddlewares finds fields of type `Middleware`, parses their tags,
 // and constructs `Middleware` objects. The `middleware` tag defines a single middleware name,
 // while the `middlewares` tag can define multiple middleware names that this middleware will apply.
@@ -251,17 +491,33 @@ func (h *Handler) parseRouteTag(tag string) (method string, path string, err err
 // AnalyticsMiddleware Middleware `middleware:"analytics"`
 // ```
 //
-// Each middleware can be referenced by routes through the `middlewares:"..."` tag.
-func (h *Handler) searchForMiddlewares(flatFields []reflect.StructField, foundHandlers map[string]gin.HandlerFunc) {
-	typeOfMiddleware := reflect.TypeOf(Middleware{})
+// Each middleware can be referenced by routes through the `middlewares:"..."` tag. A middleware method
+// with the `func(arg string) (gin.HandlerFunc, error)` signature is parameterized, see ParameterizedMiddleware.
+//
+// A middleware can also be declared by an exported `gin.HandlerFunc` field holding the handler itself,
+// e.g. a middleware from another library. Such fields need the `middleware` tag and no method:
+// ```go
+// RequestID gin.HandlerFunc `middleware:"requestId"`
+// ```
+func (h *Handler) searchForMiddlewares(flatFields []reflect.StructField, foundHandlers map[string]gin.HandlerFunc, foundFactories map[string]ParameterizedMiddleware, funcFields map[string]gin.HandlerFunc) {
 	middlewares := make([]*Middleware, 0)
 
 	for _, fieldType := range flatFields {
-		if fieldType.Type != typeOfMiddleware {
+		if fieldType.Type == ginHandlerFuncType && funcFields[fieldType.Name] != nil && fieldType.Tag.Get(MiddlewareTag) != "" {
+			middlewares = append(middlewares, &Middleware{
+				handler:     funcFields[fieldType.Name],
+				middleware:  strings.ToLower(fieldType.Tag.Get(MiddlewareTag)),
+				middlewares: parseMiddlewaresTag(fieldType.Tag.Get(MiddlewaresTag)),
+			})
+
+			continue
+		}
+
+		if !isMarker(middlewareMarkers, fieldType.Type) {
 			continue
 		}
 
-		if foundHandlers[fieldType.Name] != nil {
+		if foundHandlers[fieldType.Name] != nil || foundFactories[fieldType.Name] != nil {
 			middlewareName := fieldType.Tag.Get(MiddlewareTag)
 			if middlewareName == "" {
 				middlewareName = fieldType.Name
@@ -269,8 +525,9 @@ func (h *Handler) searchForMiddlewares(flatFields []reflect.StructField, foundHa
 
 			m := &Middleware{
 				handler:     foundHandlers[fieldType.Name],
+				factory:     foundFactories[fieldType.Name],
 				middleware:  strings.ToLower(middlewareName),
-				middlewares: h.parseMiddlewaresTag(fieldType.Tag.Get(MiddlewaresTag)),
+				middlewares: parseMiddlewaresTag(fieldType.Tag.Get(MiddlewaresTag)),
 			}
 
 			middlewares = append(middlewares, m)
@@ -282,26 +539,50 @@ func (h *Handler) searchForMiddlewares(flatFields []reflect.StructField, foundHa
 
 // getAllGinHandlers scans the given reflected value (struct) for methods
 // that match the signature `func(*gin.Context)` and returns them in a map keyed by method name.
-// These methods can be route handlers or middleware handlers.
-func (h *Handler) getAllGinHandlers(rv reflect.Value) (map[string]gin.HandlerFunc, map[string]*casualHandler) {
-	rt := rv.Type()
+// These methods can be route handlers or middleware handlers. Casual, websocket and static handler methods
+// and parameterized middlewares are returned in their own maps.
+//
+// Handler methods must be exported: unexported methods are not part of the reflected method set, and methods
+// that cannot be accessed through reflection are skipped, so route and middleware fields referring to them are ignored.
+//
+// The signature scan depends on the type only and is cached per type (see handlerMethodsCache),
+// so only binding the methods to rv is repeated for further instances.
+func (h *Handler) getAllGinHandlers(rv reflect.Value) (map[string]gin.HandlerFunc, map[string]*casualHandler, map[string]websocketHandler, map[string]reflect.Value, map[string]ParameterizedMiddleware) {
 	handlers := make(map[string]gin.HandlerFunc)
 	casualHandlers := make(map[string]*casualHandler)
+	websocketHandlers := make(map[string]websocketHandler)
+	staticHandlers := make(map[string]reflect.Value)
+	middlewareFactories := make(map[string]ParameterizedMiddleware)
 
-	for i := 0; i < rt.NumMethod(); i++ {
-		method := rt.Method(i)
+	for _, method := range handlerMethodsOf(rv.Type()) {
+		if !rv.Method(method.Index).CanInterface() {
+			continue
+		}
 
-		if isSimpleGinHandler(method.Type) {
-			handlers[method.Name] = rv.Method(i).Interface().(func(*gin.Context))
-		} else if isCasualHandler(method.Type) {
+		switch method.kind {
+		case ginHandlerKind:
+			if handler, ok := rv.Method(method.Index).Interface().(func(*gin.Context)); ok {
+				handlers[method.Name] = handler
+			}
+		case websocketHandlerKind:
+			if handler := asWebsocketHandler(rv.Method(method.Index).Interface()); handler != nil {
+				websocketHandlers[method.Name] = handler
+			}
+		case staticHandlerKind:
+			staticHandlers[method.Name] = rv.Method(method.Index)
+		case parameterizedMiddlewareKind:
+			if factory := asParameterizedMiddleware(rv.Method(method.Index).Interface()); factory != nil {
+				middlewareFactories[method.Name] = factory
+			}
+		case casualHandlerKind:
 			casualHandlers[method.Name] = &casualHandler{
 				rv: &rv,
-				rm: &method,
+				rm: &method.Method,
 			}
 		}
 	}
 
-	return handlers, casualHandlers
+	return handlers, casualHandlers, websocketHandlers, staticHandlers, middlewareFactories
 }
 
 // searchForGroups finds fields of type `Group`, parses the `group` tag to identify the path prefix,
@@ -323,12 +604,17 @@ func (h *Handler) getAllGinHandlers(rv reflect.Value) (map[string]gin.HandlerFun
 // ```
 //
 // This creates a group named "v3" with a path prefix "/api/v3". Routes referencing `group:"v3"` will be placed under `/api/v3`.
+//
+// Groups can be nested with the `parent` tag, which makes the group path relative to the parent group:
+// ```go
+// Admin Group `group:"/admin" parent:"v3"`
+// ```
+// Routes referencing `group:"admin"` will be placed under `/api/v3/admin`.
 func (h *Handler) searchForGroups(flatFields []reflect.StructField) error {
-	typeOfGroup := reflect.TypeOf(Group{})
 	groups := make([]*Group, 0)
 
 	for _, field := range flatFields {
-		if field.Type != typeOfGroup {
+		if !isMarker(groupMarkers, field.Type) {
 			continue
 		}
 
@@ -345,9 +631,11 @@ func (h *Handler) searchForGroups(flatFields []reflect.StructField) error {
 
 			middlewaresTagValue := field.Tag.Get(MiddlewaresTag)
 			if middlewaresTagValue != "" {
-				group.middlewares = h.parseMiddlewaresTag(middlewaresTagValue)
+				group.middlewares = parseMiddlewaresTag(middlewaresTagValue)
 			}
 
+			group.parent = parseGroupReference(field.Tag.Get(ParentTag))
+
 			groups = append(groups, group)
 		}
 	}
@@ -357,34 +645,113 @@ func (h *Handler) searchForGroups(flatFields []reflect.StructField) error {
 }
 
 // getAllReflectionFieldsRecursive recursively extracts all fields (including those from embedded and nested structs)
-// from the given reflected value.
-func (h *Handler) getAllReflectionFieldsRecursive(rv reflect.Value) []reflect.StructField {
-	if rv.Kind() == reflect.Ptr {
-		rv = rv.Elem()
+// from the given reflected value. Embedded pointers to structs are followed even when nil, and embedded interfaces
+// are followed through their dynamic value. Values that are not structs contribute no fields.
+//
+// The non-nil values of exported `gin.HandlerFunc` fields are also returned, keyed by field name,
+// so such fields can declare middlewares without a method.
+//
+// The scan is cached per type (see reflectionFieldsCache) unless it followed an embedded interface,
+// whose dynamic value may differ between instances of the type.
+func (h *Handler) getAllReflectionFieldsRecursive(rv reflect.Value) ([]reflect.StructField, map[string]gin.HandlerFunc) {
+	if cached, ok := reflectionFieldsCache.Load(rv.Type()); ok {
+		fields := cached.(*reflectionFields)
+
+		return fields.fields, fields.funcFieldValues(rv)
+	}
+
+	funcFields := make(map[string]gin.HandlerFunc)
+	scanned := &reflectionFields{funcFieldIndexes: make(map[string][]int)}
+	scanned.fields = h.collectReflectionFields(rv, make(map[reflect.Type]struct{}), nil, funcFields, scanned)
+
+	if !scanned.dynamic {
+		reflectionFieldsCache.Store(rv.Type(), scanned)
+	}
+
+	return scanned.fields, funcFields
+}
+
+// collectReflectionFields implements getAllReflectionFieldsRecursive. The struct types currently being scanned
+// are tracked in visited, so self-referential structs (e.g. a struct embedding a pointer to itself) are scanned
+// only once instead of recursing forever. The index path of every `gin.HandlerFunc` field, relative to index,
+// is recorded in scanned so the field values of other instances can be read without scanning again.
+func (h *Handler) collectReflectionFields(rv reflect.Value, visited map[reflect.Type]struct{}, index []int, funcFields map[string]gin.HandlerFunc, scanned *reflectionFields) []reflect.StructField {
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv = reflect.Zero(rv.Type().Elem())
+		} else {
+			rv = rv.Elem()
+		}
+	case reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+
+		scanned.dynamic = true
+
+		return h.collectReflectionFields(rv.Elem(), visited, index, funcFields, scanned)
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil
 	}
 
 	rt := rv.Type()
+	if _, ok := visited[rt]; ok {
+		return nil
+	}
+
+	visited[rt] = struct{}{}
+	defer delete(visited, rt)
+
 	fields := make([]reflect.StructField, 0)
 
 	for i := 0; i < rv.NumField(); i++ {
-		if rt.Field(i).Type.Kind() == reflect.Struct {
-			fields = append(fields, h.getAllReflectionFieldsRecursive(rv.Field(i))...)
+		field := rt.Field(i)
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			fields = append(fields, h.collectReflectionFields(rv.Field(i), visited, fieldIndex, funcFields, scanned)...)
+		case reflect.Ptr, reflect.Interface:
+			if field.Anonymous {
+				fields = append(fields, h.collectReflectionFields(rv.Field(i), visited, fieldIndex, funcFields, scanned)...)
+			}
+		case reflect.Func:
+			if field.Type == ginHandlerFuncType && field.IsExported() {
+				scanned.funcFieldIndexes[field.Name] = fieldIndex
+
+				if !rv.Field(i).IsNil() {
+					funcFields[field.Name] = rv.Field(i).Interface().(gin.HandlerFunc)
+				} else {
+					delete(funcFields, field.Name)
+				}
+			}
 		}
-		fields = append(fields, rt.Field(i))
+		fields = append(fields, field)
 	}
 
 	return fields
 }
 
 // parseMiddlewaresTag splits a comma-separated list of middleware names from a struct tag,
-// trims spaces, converts them to lowercase, and returns them as a slice of strings.
-func (h *Handler) parseMiddlewaresTag(tag string) []string {
+// trims spaces, converts them to lowercase, and returns them as a slice of strings. An entry can pass
+// an argument to a parameterized middleware after `=`, e.g. "cache=30s", which keeps its case.
+func parseMiddlewaresTag(tag string) []string {
 	result := make([]string, 0)
 
 	values := strings.Split(tag, ",")
 	for _, v := range values {
 		v = strings.TrimSpace(v)
-		if v != "" {
+		if v == "" {
+			continue
+		}
+
+		// Only the name is case-insensitive, the argument of a parameterized middleware is kept as is
+		if name, arg, hasArg := splitMiddlewareEntry(v); hasArg {
+			result = append(result, strings.ToLower(strings.TrimSpace(name))+"="+strings.TrimSpace(arg))
+		} else {
 			result = append(result, strings.ToLower(v))
 		}
 	}
@@ -392,6 +759,13 @@ func (h *Handler) parseMiddlewaresTag(tag string) []string {
 	return result
 }
 
+// parseGroupReference normalizes a group name referenced by a route `group` tag or a group `parent` tag.
+// Group names are derived from field names and lowercased, so references are matched case-insensitively
+// and resolve regardless of the handler or the order in which the group is declared.
+func parseGroupReference(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
 // parseGroupTagRequest holds data required to parse a group tag from a struct field.
 type parseGroupTagRequest struct {
 	tagValue string
@@ -437,11 +811,18 @@ func (h *Handler) parseGroupTag(req *parseGroupTagRequest) (*Group, error) {
 // Route defines an HTTP endpoint with a method, path, associated handler, and optional middlewares or group prefix.
 //
 // Fields:
+// - `name`: The name of the describer field declaring the route.
 // - `method`: The HTTP method (e.g., "GET", "POST").
 // - `path`: The HTTP path (e.g., "/checkout/apply").
 // - `handler`: The Gin handler function that processes the request.
+// - `websocket`: The websocket handler of `WS` routes, bound to the upgrader of the engine when registered.
 // - `middlewares`: A list of middleware names applied before the handler.
 // - `group`: The name of the group this route belongs to, if any.
+// - `constraints`: Format constraints checked against path parameters before the handler runs.
+// - `consumes`: The media types of the `consumes` tag the request content type must match, see consumesMiddleware.
+// - `metadata`: The key/value pairs of the `meta` tag, see RouteMetadata.
+// - `timeout`: The duration after which the request context is cancelled, or zero for no timeout.
+// - `invalidTimeout`: The raw `timeout` tag value when it could not be parsed.
 //
 // **Example:**
 // ```go
@@ -453,11 +834,19 @@ func (h *Handler) parseGroupTag(req *parseGroupTagRequest) (*Group, error) {
 // ```
 // This defines a GET route at `/api/v3/products` that applies "auth" and "logging" middleware.
 type Route struct {
+	name        string
 	middlewares []string
 	group       string
 	method      string
 	path        string
 	handler     gin.HandlerFunc
+	websocket   websocketHandler
+	constraints []*paramConstraint
+	consumes    []string
+	metadata    map[string]string
+
+	timeout        time.Duration
+	invalidTimeout string
 }
 
 // Middleware defines a middleware associated with a handler function and possibly other nested middlewares.
@@ -483,6 +872,7 @@ type Route struct {
 // Here, the `ApplyCart` route will be executed with the "log", "cors", and "analytics" middleware in the defined order.
 type Middleware struct {
 	handler     gin.HandlerFunc
+	factory     ParameterizedMiddleware
 	middleware  string
 	middlewares []string
 }
@@ -493,6 +883,7 @@ type Middleware struct {
 // - `name`: The group's name, derived from the field name (e.g., "v3" from "V3").
 // - `Path`: The prefix path for all routes in this group (e.g., "/api/v3").
 // - `Middlewares`: A list of middleware names applied to all routes in the group.
+// - `parent`: The name of the parent group this group is nested in, if any (from the `parent` tag).
 //
 // **Example:**
 //
@@ -513,6 +904,26 @@ type Group struct {
 	name        string
 	Path        string
 	middlewares []string
+	parent      string
+}
+
+// NewGroup creates a group outside of the handler tags, e.g. when its prefix comes from configuration.
+// Register it with WithGroups; routes reference it through their `group` tag by name, matched
+// case-insensitively. The middlewares are applied to all routes in the group, as with the `middlewares` tag.
+//
+// **Example:**
+// ```go
+//
+//	api := httpbara.NewGroup("api", "/api/"+cfg.Version, "auth")
+//	engine, _ := httpbara.New(handlers, httpbara.WithGroups(api))
+//
+// ```
+func NewGroup(name, path string, middlewares ...string) *Group {
+	return &Group{
+		name:        parseGroupReference(name),
+		Path:        path,
+		middlewares: parseMiddlewaresTag(strings.Join(middlewares, ",")),
+	}
 }
 
 func isSimpleGinHandler(t reflect.Type) bool {
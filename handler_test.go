@@ -0,0 +1,208 @@
+package httpbara_test
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"testing"
+)
+
+type pingDescriber struct {
+	Ping httpbara.Route `route:"GET /ping"`
+}
+
+func ping(ctx *gin.Context) {
+	ctx.String(http.StatusOK, "pong")
+}
+
+// assertPing checks that the handler created from describer serves GET /ping.
+func assertPing(t *testing.T, describer any) {
+	t.Helper()
+
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, describer)})
+	if rec := serve(h, http.MethodGet, "/ping", nil, nil); rec.Code != http.StatusOK || rec.Body.String() != "pong" {
+		t.Fatalf("GET /ping = %d %q, want %d %q", rec.Code, rec.Body.String(), http.StatusOK, "pong")
+	}
+}
+
+// pointerEmbedHandler declares its routes in a describer embedded by pointer.
+type pointerEmbedHandler struct {
+	*pingDescriber
+}
+
+func (h *pointerEmbedHandler) Ping(ctx *gin.Context) {
+	ping(ctx)
+}
+
+// interfaceEmbedHandler declares its routes in the dynamic value of an embedded interface.
+type interfaceEmbedHandler struct {
+	any
+}
+
+func (h *interfaceEmbedHandler) Ping(ctx *gin.Context) {
+	ping(ctx)
+}
+
+func TestAsHandlerEmbeddedDescribers(t *testing.T) {
+	tests := []struct {
+		name      string
+		describer any
+	}{
+		{name: "nil pointer", describer: &pointerEmbedHandler{}},
+		{name: "pointer", describer: &pointerEmbedHandler{pingDescriber: &pingDescriber{}}},
+		{name: "interface holding a struct", describer: &interfaceEmbedHandler{any: pingDescriber{}}},
+		{name: "interface holding a pointer", describer: &interfaceEmbedHandler{any: &pingDescriber{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertPing(t, tt.describer)
+		})
+	}
+}
+
+func TestAsHandlerEmbeddedNilInterface(t *testing.T) {
+	handler := mustHandler(t, &interfaceEmbedHandler{})
+
+	h := newTestEngine(t, []*httpbara.Handler{handler})
+	if rec := serve(h, http.MethodGet, "/ping", nil, nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("GET /ping = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+// selfReferentialHandler refers to its own type, directly and through a mutually recursive type.
+type selfReferentialHandler struct {
+	pingDescriber
+	*selfReferentialHandler
+
+	Next  *selfReferentialHandler
+	Other mutuallyRecursive
+}
+
+type mutuallyRecursive struct {
+	*selfReferentialHandler
+}
+
+func (h *selfReferentialHandler) Ping(ctx *gin.Context) {
+	ping(ctx)
+}
+
+func TestAsHandlerSelfReferential(t *testing.T) {
+	tests := []struct {
+		name      string
+		describer any
+	}{
+		{name: "zero value", describer: &selfReferentialHandler{}},
+		{name: "linked values", describer: &selfReferentialHandler{Next: &selfReferentialHandler{}}},
+		{name: "cycle", describer: func() any {
+			h := &selfReferentialHandler{}
+			h.selfReferentialHandler, h.Next, h.Other.selfReferentialHandler = h, h, h
+
+			return h
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertPing(t, tt.describer)
+		})
+	}
+}
+
+// mixedExportHandler has unexported helpers next to its exported handler methods.
+type mixedExportHandler struct {
+	pingDescriber
+
+	middleware gin.HandlerFunc
+}
+
+func (h *mixedExportHandler) Ping(ctx *gin.Context) {
+	h.pong(ctx)
+}
+
+func (h *mixedExportHandler) pong(ctx *gin.Context) {
+	ping(ctx)
+}
+
+type unexportedRouteDescriber struct {
+	pong httpbara.Route `route:"GET /pong"`
+}
+
+// unexportedRouteHandler refers to an unexported method, which is not part of its method set.
+type unexportedRouteHandler struct {
+	unexportedRouteDescriber
+}
+
+func (h *unexportedRouteHandler) pong(ctx *gin.Context) {
+	ping(ctx)
+}
+
+func TestAsHandlerUnexportedMethods(t *testing.T) {
+	assertPing(t, &mixedExportHandler{middleware: ping})
+
+	if _, err := httpbara.AsHandler(&unexportedRouteHandler{}); !errors.Is(err, httpbara.ErrUnimplementedRoute) {
+		t.Fatalf("err = %v, want %v", err, httpbara.ErrUnimplementedRoute)
+	}
+}
+
+// setHeader returns a middleware setting a response header, standing in for one imported from another library.
+func setHeader(key, value string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header(key, value)
+	}
+}
+
+type mixedMiddlewareDescriber struct {
+	Method httpbara.Middleware `middleware:"method"`
+	Value  gin.HandlerFunc     `middleware:"Value"`
+
+	Ping httpbara.Route `route:"GET /ping" middlewares:"method,value"`
+}
+
+// mixedMiddlewareHandler declares a middleware by method and another one by value.
+type mixedMiddlewareHandler struct {
+	mixedMiddlewareDescriber
+}
+
+func (h *mixedMiddlewareHandler) Method(ctx *gin.Context) {
+	ctx.Header("X-Method", "method")
+}
+
+func (h *mixedMiddlewareHandler) Ping(ctx *gin.Context) {
+	ping(ctx)
+}
+
+func TestAsHandlerMiddlewareValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     gin.HandlerFunc
+		wantValue string
+	}{
+		{name: "value set", value: setHeader("X-Value", "first"), wantValue: "first"},
+		{name: "other instance", value: setHeader("X-Value", "second"), wantValue: "second"},
+		{name: "nil value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			describer := &mixedMiddlewareHandler{}
+			describer.Value = tt.value
+
+			h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, describer)})
+
+			rec := serve(h, http.MethodGet, "/ping", nil, nil)
+			if rec.Code != http.StatusOK || rec.Body.String() != "pong" {
+				t.Fatalf("GET /ping = %d %q, want %d %q", rec.Code, rec.Body.String(), http.StatusOK, "pong")
+			}
+
+			if got := rec.Header().Get("X-Method"); got != "method" {
+				t.Fatalf("X-Method = %q, want the method middleware to run", got)
+			}
+
+			if got := rec.Header().Get("X-Value"); got != tt.wantValue {
+				t.Fatalf("X-Value = %q, want %q", got, tt.wantValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package httpbara
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+	"github.com/gopybara/httpbara/common"
+	"sync/atomic"
+)
+
+var (
+	ErrShutdownStateNotSet = errors.New("shutdown state is not set")
+)
+
+// ShutdownState is a flag shared between the engine and the health handler. The engine marks it as
+// shutting down the moment a termination signal is received, before the pre-shutdown delay and the
+// drain, so the readiness probe starts failing while in-flight requests are still being served.
+type ShutdownState struct {
+	shuttingDown atomic.Bool
+}
+
+// NewShutdownState creates a ShutdownState that is not shutting down yet.
+// Pass it to both WithShutdownState and NewHealthHandler.
+func NewShutdownState() *ShutdownState {
+	return &ShutdownState{}
+}
+
+// MarkShuttingDown flips the state to shutting down. Calling it more than once has no further effect.
+func (s *ShutdownState) MarkShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether a shutdown was initiated.
+func (s *ShutdownState) ShuttingDown() bool {
+	return s.shuttingDown.Load()
+}
+
+type healthHandlerDescriber struct {
+	Liveness  Route `route:"GET /livez"`
+	Readiness Route `route:"GET /readyz"`
+}
+
+type healthHandler struct {
+	healthHandlerDescriber
+
+	state *ShutdownState
+}
+
+// NewHealthHandler creates a handler serving the `GET /livez` liveness and `GET /readyz` readiness probes.
+// Readiness reports 503 as soon as the given state is marked as shutting down.
+//
+// **Example:**
+// ```go
+//
+//	state := httpbara.NewShutdownState()
+//	health, _ := httpbara.NewHealthHandler(state)
+//	engine, _ := httpbara.New([]*httpbara.Handler{health, api},
+//	    httpbara.WithShutdownState(state),
+//	    httpbara.WithPreShutdownDelay(5*time.Second),
+//	)
+//
+// ```
+func NewHealthHandler(state *ShutdownState) (*Handler, error) {
+	if state == nil {
+		return nil, ErrShutdownStateNotSet
+	}
+
+	hh := healthHandler{
+		state: state,
+	}
+
+	return AsHandler(&hh)
+}
+
+func (hh *healthHandler) Liveness(ctx *gin.Context) {
+	ctx.JSON(casual.NewHTTPResponse(common.Ptr("ok")))
+}
+
+func (hh *healthHandler) Readiness(ctx *gin.Context) {
+	if hh.state.ShuttingDown() {
+		ctx.JSON(casual.NewHttpErrorResponse(ErrShutdown))
+		return
+	}
+
+	ctx.JSON(casual.NewHTTPResponse(common.Ptr("ok")))
+}
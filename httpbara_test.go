@@ -0,0 +1,57 @@
+package httpbara_test
+
+import (
+	"github.com/gopybara/httpbara"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// discardLogger is a httpbara.Logger dropping every message, keeping the test output readable.
+type discardLogger struct{}
+
+func (discardLogger) Info(string, ...any)  {}
+func (discardLogger) Debug(string, ...any) {}
+func (discardLogger) Error(string, ...any) {}
+func (discardLogger) Panic(string, ...any) {}
+func (discardLogger) Warn(string, ...any)  {}
+
+// newTestEngine builds the engine of handlers with opts, without logging, and returns it as an http.Handler.
+func newTestEngine(t *testing.T, handlers []*httpbara.Handler, opts ...httpbara.ParamsCb) http.Handler {
+	t.Helper()
+
+	opts = append([]httpbara.ParamsCb{httpbara.WithLogger(discardLogger{})}, opts...)
+
+	engine, err := httpbara.New(handlers, opts...)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	return engine.AsHTTPHandler()
+}
+
+// mustHandler returns the handler of describer, failing the test if it cannot be created.
+func mustHandler(t *testing.T, describer any) *httpbara.Handler {
+	t.Helper()
+
+	handler, err := httpbara.AsHandler(describer)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+
+	return handler
+}
+
+// serve records the response of h to a request with the given method, target, body and headers.
+func serve(h http.Handler, method, target string, body io.Reader, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, body)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec
+}
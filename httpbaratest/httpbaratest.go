@@ -0,0 +1,126 @@
+// Package httpbaratest serves httpbara handlers with httptest, so that they can be tested end to end
+// without binding a port or running the signal loop of Engine.Run.
+package httpbaratest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gopybara/httpbara"
+	"github.com/gopybara/httpbara/casual"
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+// ErrNoData is returned by Response.Decode when the response has no `data` in its envelope.
+var ErrNoData = errors.New("response has no data")
+
+// NewServer builds the engine of handlers with opts, exactly as httpbara.New does, and serves it
+// with httptest.NewServer. The caller closes the server.
+//
+// **Example:**
+// ```go
+//
+//	srv, err := httpbaratest.NewServer([]*httpbara.Handler{handler})
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer srv.Close()
+//
+//	resp, err := httpbaratest.RequestJSON(srv, http.MethodPost, "/api/v3/products", product)
+//
+// ```
+func NewServer(handlers []*httpbara.Handler, opts ...httpbara.ParamsCb) (*httptest.Server, error) {
+	engine, err := httpbara.New(handlers, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create engine: %w", err)
+	}
+
+	return httptest.NewServer(engine.AsHTTPHandler()), nil
+}
+
+// Response is a response of the test server with its casual envelope decoded.
+//
+// Fields:
+// - `StatusCode`: The HTTP status code of the response.
+// - `Header`: The response headers.
+// - `Body`: The raw response body, e.g. for bare responses (see httpbara.WithBareResponses).
+// - `Data`: The `data` of a casual.HttpResponse, undecoded (see Decode).
+// - `Meta`: The `meta` of the envelope, nil if none.
+// - `Error`: The `error` of a casual.HttpErrorResponse, nil if the response is not an error.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Data       json.RawMessage
+	Meta       map[string]any
+	Error      *casual.HttpError
+}
+
+// Decode unmarshals the `data` of the envelope into v.
+func (r *Response) Decode(v any) error {
+	if len(r.Data) == 0 {
+		return ErrNoData
+	}
+
+	return json.Unmarshal(r.Data, v)
+}
+
+type envelope struct {
+	Data  json.RawMessage   `json:"data"`
+	Meta  map[string]any    `json:"meta"`
+	Error *casual.HttpError `json:"error"`
+}
+
+// RequestJSON sends a request to the test server with body encoded as JSON (no body when nil), accepting JSON,
+// and decodes the casual envelope of the response. Bodies that are not an envelope, such as those of bare
+// responses or plain Gin handlers, are only available in Response.Body.
+func RequestJSON(srv *httptest.Server, method, path string, body any) (*Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, srv.URL+path, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	response := &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       respBody,
+	}
+
+	var env envelope
+	if json.Unmarshal(respBody, &env) == nil {
+		response.Data = env.Data
+		response.Meta = env.Meta
+		response.Error = env.Error
+	}
+
+	return response, nil
+}
@@ -0,0 +1,89 @@
+package httpbara
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+	"golang.org/x/text/language"
+)
+
+const languageKey = "language"
+
+var (
+	ErrNoSupportedLanguages = errors.New("no supported languages configured")
+)
+
+type languageMiddlewareDescriber struct {
+	Middleware Middleware `middleware:"language"`
+}
+
+type languageMiddleware struct {
+	languageMiddlewareDescriber
+
+	supported []string
+	matcher   language.Matcher
+}
+
+// NewLanguageMiddleware creates a middleware named "language" that resolves the response language from the
+// Accept-Language header against the supported languages, e.g. `[]string{"en", "de", "ru"}`. The first supported
+// language is the fallback used when the header is missing or nothing matches.
+//
+// The resolved language is stored in the context (see GetLanguage) and passed to the casual responders
+// through casual.WithLang, so validation messages are rendered in that language.
+func NewLanguageMiddleware(supported []string) (*Handler, error) {
+	if len(supported) == 0 {
+		return nil, ErrNoSupportedLanguages
+	}
+
+	tags := make([]language.Tag, 0, len(supported))
+	for _, lang := range supported {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, fmt.Errorf("invalid supported language %q: %w", lang, err)
+		}
+
+		tags = append(tags, tag)
+	}
+
+	lm := languageMiddleware{
+		supported: supported,
+		matcher:   language.NewMatcher(tags),
+	}
+
+	return AsHandler(&lm)
+}
+
+func (lm *languageMiddleware) Middleware(ctx *gin.Context) {
+	// a malformed header yields no preferences and resolves to the fallback
+	preferred, _, _ := language.ParseAcceptLanguage(ctx.GetHeader("Accept-Language"))
+	_, index, _ := lm.matcher.Match(preferred...)
+
+	ctx.Set(languageKey, lm.supported[index])
+
+	ctx.Next()
+}
+
+// GetLanguage returns the language resolved by the language middleware, or an empty string
+// when the middleware was not applied to the route.
+func GetLanguage(ctx *gin.Context) string {
+	return ctx.GetString(languageKey)
+}
+
+// languageParams returns the casual response params carrying the response language: the language resolved
+// by the language middleware or, without the middleware, the base language most preferred by the
+// Accept-Language header. Without either no language is set and responders use their default.
+func languageParams(ctx *gin.Context) []casual.HttpResponseParamsCb {
+	if lang := GetLanguage(ctx); lang != "" {
+		return []casual.HttpResponseParamsCb{casual.WithLang(lang)}
+	}
+
+	preferred, _, err := language.ParseAcceptLanguage(ctx.GetHeader("Accept-Language"))
+	if err != nil || len(preferred) == 0 {
+		return nil
+	}
+
+	base, _ := preferred[0].Base()
+
+	return []casual.HttpResponseParamsCb{casual.WithLang(base.String())}
+}
@@ -0,0 +1,89 @@
+package httpbara_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gopybara/httpbara"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type localizedHandlerDescriber struct {
+	Plain      httpbara.Route `route:"POST /plain"`
+	Negotiated httpbara.Route `route:"POST /negotiated" middlewares:"language"`
+}
+
+type localizedHandler struct {
+	localizedHandlerDescriber
+}
+
+func (h *localizedHandler) Plain(ctx context.Context, req *requiredRequest) (*requiredRequest, error) {
+	return req, nil
+}
+
+func (h *localizedHandler) Negotiated(ctx context.Context, req *requiredRequest) (*requiredRequest, error) {
+	return req, nil
+}
+
+func TestLocalizedValidationErrors(t *testing.T) {
+	language, err := httpbara.NewLanguageMiddleware([]string{"en", "ru", "es"})
+	if err != nil {
+		t.Fatalf("failed to create language middleware: %v", err)
+	}
+
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &localizedHandler{}), language})
+
+	const (
+		english = "Field is required"
+		russian = "Поле обязательно для заполнения"
+		spanish = "El campo es obligatorio"
+	)
+
+	tests := []struct {
+		name     string
+		path     string
+		language string
+		issue    string
+	}{
+		{name: "russian", path: "/plain", language: "ru", issue: russian},
+		{name: "spanish", path: "/plain", language: "es", issue: spanish},
+		{name: "regional english", path: "/plain", language: "en-US", issue: english},
+		{name: "regional russian", path: "/plain", language: "ru-RU,en;q=0.5", issue: russian},
+		{name: "unsupported language", path: "/plain", language: "fr", issue: english},
+		{name: "no header", path: "/plain", issue: english},
+		{name: "middleware russian", path: "/negotiated", language: "ru", issue: russian},
+		{name: "middleware spanish", path: "/negotiated", language: "es-MX", issue: spanish},
+		{name: "middleware regional english", path: "/negotiated", language: "en-US", issue: english},
+		{name: "middleware unsupported language", path: "/negotiated", language: "fr", issue: english},
+		{name: "middleware next preference", path: "/negotiated", language: "fr, es;q=0.8", issue: spanish},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{"Content-Type": "application/json"}
+			if tt.language != "" {
+				headers["Accept-Language"] = tt.language
+			}
+
+			rec := serve(h, http.MethodPost, tt.path, strings.NewReader(`{}`), headers)
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+			}
+
+			var resp casual.HttpErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if resp.Error == nil || len(resp.Error.Details) != 1 {
+				t.Fatalf("error = %+v, want a single detail", resp.Error)
+			}
+
+			if issue := resp.Error.Details[0].Issue; issue != tt.issue {
+				t.Errorf("issue = %q, want %q", issue, tt.issue)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package httpbara
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrEngineRunning is returned by Start, StartMulti, Run and RunMulti when the engine already serves.
+	ErrEngineRunning = errors.New("engine is already running")
+
+	// ErrEngineNotRunning is returned by Stop when the engine does not serve.
+	ErrEngineNotRunning = errors.New("engine is not running")
+
+	// ErrEngineStopped is returned by Start, StartMulti, Run and RunMulti when a previous shutdown terminated
+	// the task tracker set via WithTaskTracker. Only the tracker the engine creates itself is renewed on start.
+	ErrEngineStopped = errors.New("engine was stopped and its task tracker cannot be renewed")
+)
+
+// runState tracks the servers started by StartMulti until they are shut down.
+//
+// Fields:
+// - `listeners`: The listeners served by the engine.
+// - `wg`: Counts the listeners and additional servers still serving.
+// - `errs`: The errors the servers failed with, guarded by `mu`.
+// - `failed`: Closed once the first server stops serving, as the engine no longer serves as configured then.
+// - `stopped`: Closed once the servers are shut down, `stopErr` holding the result.
+type runState struct {
+	listeners []*listener
+	wg        sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+
+	failed     chan struct{}
+	failedOnce sync.Once
+
+	stopped chan struct{}
+	stopErr error
+}
+
+// done records that a server stopped serving, with the error it failed with if any.
+func (rs *runState) done(err error) {
+	if err != nil {
+		rs.mu.Lock()
+		rs.errs = append(rs.errs, fmt.Errorf("server failed: %w", err))
+		rs.mu.Unlock()
+	}
+
+	rs.failedOnce.Do(func() {
+		close(rs.failed)
+	})
+	rs.wg.Done()
+}
+
+// listenConfig describes the listener Run and Start serve for addr, see Run.
+func (c *core) listenConfig(addr string) ListenConfig {
+	if addr == "" {
+		addr = c.addr
+	}
+
+	config := ListenConfig{
+		Addr:      addr,
+		CertFile:  c.tlsCertFile,
+		KeyFile:   c.tlsKeyFile,
+		TLSConfig: c.tlsConfig,
+	}
+
+	if c.listener != nil {
+		config.Listener = c.listener
+	} else if c.unixSocket != "" {
+		config.Network = "unix"
+		config.Addr = c.unixSocket
+	}
+
+	return config
+}
+
+// Start serves the routes like Run, but returns as soon as the server listens instead of waiting for a signal.
+// Signals are not handled: call Stop to shut the server down. Addr reports the bound address once Start returned.
+//
+// Example:
+// ```go
+//
+//	if err := engine.Start(":0"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer engine.Stop(context.Background())
+//
+// ```
+func (c *core) Start(addr string) error {
+	return c.StartMulti([]ListenConfig{c.listenConfig(addr)})
+}
+
+// StartMulti serves the routes on every listener config like RunMulti, but returns as soon as all addresses
+// are bound instead of waiting for a signal. A server failing later is reported by Stop.
+func (c *core) StartMulti(configs []ListenConfig) error {
+	_, err := c.start(configs)
+
+	return err
+}
+
+// Stop gracefully shuts down the servers started by Start, StartMulti, Run or RunMulti, which then returns the
+// same result. It marks the engine as shutting down and waits for the delay configured via WithPreShutdownDelay,
+// then waits for the in-flight requests like a shutdown on a signal does. Unlike the shutdown timeout used by Run,
+// ctx bounds both the delay and the shutdown. Stop returns ErrEngineNotRunning when the engine does not serve.
+// A stopped engine can be started again, unless its task tracker was set via WithTaskTracker (see ErrEngineStopped).
+func (c *core) Stop(ctx context.Context) error {
+	c.runMu.Lock()
+	rs := c.run
+	c.run = nil
+	c.runMu.Unlock()
+
+	if rs == nil {
+		return ErrEngineNotRunning
+	}
+
+	c.preShutdown(ctx, rs)
+
+	return c.shutdown(ctx, rs)
+}
+
+// start binds every listener config and starts serving them together with the servers registered via WithServers.
+func (c *core) start(configs []ListenConfig) (*runState, error) {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+
+	if c.run != nil {
+		return nil, ErrEngineRunning
+	}
+
+	if err := c.renewTaskTracker(); err != nil {
+		return nil, err
+	}
+
+	handler := c.handler()
+
+	listeners, err := c.openListeners(configs, handler)
+	if err != nil {
+		return nil, fmt.Errorf("server failed to start: %w", err)
+	}
+
+	c.setListenerAddrs(listeners)
+
+	rs := &runState{
+		listeners: listeners,
+		failed:    make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+
+	rs.wg.Add(len(listeners) + len(c.servers))
+	for _, l := range listeners {
+		go func(l *listener) {
+			rs.done(l.serve())
+		}(l)
+	}
+
+	for _, srv := range c.servers {
+		go func(srv Server) {
+			if err := srv.Serve(handler); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				rs.done(err)
+				return
+			}
+
+			rs.done(nil)
+		}(srv)
+	}
+
+	c.run = rs
+
+	return rs, nil
+}
+
+// renewTaskTracker replaces the task tracker terminated by a previous shutdown with a fresh one, so a stopped
+// engine serves requests again instead of answering them with ErrShutdown. The shutdown state is reset as well.
+func (c *core) renewTaskTracker() error {
+	c.trackerMu.Lock()
+	defer c.trackerMu.Unlock()
+
+	if !c.trackerStopped {
+		return nil
+	}
+
+	if !c.ownsTaskTracker {
+		return ErrEngineStopped
+	}
+
+	c.taskTracker = NewActiveTaskTracker()
+	c.trackerStopped = false
+
+	if c.shutdownState != nil {
+		c.shutdownState.shuttingDown.Store(false)
+	}
+
+	return nil
+}
+
+// takeRun detaches rs from the engine, reporting false when Stop already did.
+func (c *core) takeRun(rs *runState) bool {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+
+	if c.run != rs {
+		return false
+	}
+
+	c.run = nil
+
+	return true
+}
+
+// preShutdown marks the engine as shutting down and keeps serving for the delay configured via
+// WithPreShutdownDelay, unless a server fails or ctx is done first.
+func (c *core) preShutdown(ctx context.Context, rs *runState) {
+	if c.shutdownState != nil {
+		c.shutdownState.MarkShuttingDown()
+	}
+
+	if c.preShutdownDelay > 0 {
+		c.log.Info("entering pre-shutdown window, still serving", "delay", c.preShutdownDelay)
+
+		select {
+		case <-time.After(c.preShutdownDelay):
+		case <-rs.failed:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// shutdown gracefully stops the servers of rs within the deadline of ctx and aggregates every error
+// they failed or stopped with.
+func (c *core) shutdown(ctx context.Context, rs *runState) error {
+	var errs []error
+
+	// Terminate the task tracker first, so requests arriving on open connections are answered
+	// with 503 while the servers wait for the in-flight ones.
+	c.trackerMu.Lock()
+	tracker := c.taskTracker
+	c.trackerStopped = true
+	c.trackerMu.Unlock()
+
+	trackerErr := make(chan error, 1)
+	go func() {
+		trackerErr <- tracker.Shutdown(ctx)
+	}()
+
+	for _, l := range rs.listeners {
+		if err := l.srv.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("server %s shutdown failed: %w", l.config.Addr, err))
+		}
+
+		l.cleanup()
+	}
+
+	for _, srv := range c.servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("additional server shutdown failed: %w", err))
+		}
+	}
+
+	rs.wg.Wait()
+	errs = append(rs.errs, errs...)
+
+	if err := <-trackerErr; err != nil {
+		errs = append(errs, fmt.Errorf("task tracker shutdown failed: %w", err))
+	}
+
+	c.setListenerAddrs(nil)
+
+	rs.stopErr = errors.Join(errs...)
+	close(rs.stopped)
+
+	return rs.stopErr
+}
@@ -0,0 +1,90 @@
+package httpbara_test
+
+import (
+	"context"
+	"errors"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStartAfterStop(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []httpbara.ParamsCb
+		wantErr error
+	}{
+		{name: "engine task tracker is renewed"},
+		{
+			name:    "task tracker set via option",
+			opts:    []httpbara.ParamsCb{httpbara.WithTaskTracker(httpbara.NewActiveTaskTracker())},
+			wantErr: httpbara.ErrEngineStopped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := httpbara.NewShutdownState()
+			opts := append([]httpbara.ParamsCb{
+				httpbara.WithLogger(discardLogger{}),
+				httpbara.WithShutdownState(state),
+			}, tt.opts...)
+
+			engine, err := httpbara.New([]*httpbara.Handler{mustHandler(t, &pointerEmbedHandler{pingDescriber: &pingDescriber{}})}, opts...)
+			if err != nil {
+				t.Fatalf("failed to create engine: %v", err)
+			}
+
+			stop := func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				if err := engine.Stop(ctx); err != nil {
+					t.Fatalf("failed to stop engine: %v", err)
+				}
+			}
+
+			if err := engine.Start("127.0.0.1:0"); err != nil {
+				t.Fatalf("failed to start engine: %v", err)
+			}
+			assertServedStatus(t, engine, http.StatusOK)
+			stop()
+
+			err = engine.Start("127.0.0.1:0")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected restart error %v, got %v", tt.wantErr, err)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+			defer stop()
+
+			assertServedStatus(t, engine, http.StatusOK)
+
+			if state.ShuttingDown() {
+				t.Error("expected shutdown state to be reset on restart")
+			}
+
+			rec := serve(engine.AsHTTPHandler(), http.MethodGet, "/ping", nil, nil)
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected status 200 from the HTTP handler, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func assertServedStatus(t *testing.T, engine httpbara.Engine, status int) {
+	t.Helper()
+
+	resp, err := http.Get("http://" + engine.Addr().String() + "/ping")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != status {
+		t.Errorf("expected status %d, got %d", status, resp.StatusCode)
+	}
+}
@@ -0,0 +1,271 @@
+package httpbara
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"golang.org/x/net/netutil"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrNoListeners is returned by RunMulti when no listener configs were provided.
+	ErrNoListeners = errors.New("no listeners configured")
+
+	// ErrDuplicateListenAddr is returned by RunMulti when two listener configs share the same address.
+	ErrDuplicateListenAddr = errors.New("duplicate listen address")
+
+	// ErrNotASocket is returned when a Unix socket path already exists and is not a socket file.
+	ErrNotASocket = errors.New("path exists and is not a socket")
+
+	// ErrSocketInUse is returned when a Unix socket path is still served by another process.
+	ErrSocketInUse = errors.New("socket address already in use")
+
+	// ErrTLSConfigNotSet is returned by New when WithTLSConfig is given a nil config.
+	ErrTLSConfigNotSet = errors.New("tls config is not set")
+
+	// ErrListenerNotSet is returned by New when WithListener is given a nil listener.
+	ErrListenerNotSet = errors.New("listener is not set")
+)
+
+// ListenConfig describes a single listener served by the engine.
+//
+// Fields:
+// - `Network`: The network to listen on, "tcp" (default) or "unix".
+// - `Addr`: The address to listen on, e.g. ":8080" or "127.0.0.1:8443", or a socket path for "unix".
+// - `CertFile`: Path to a PEM encoded certificate. When set together with `KeyFile` the listener serves HTTPS.
+// - `KeyFile`: Path to the PEM encoded private key matching `CertFile`.
+// - `TLSConfig`: Optional TLS configuration. When set the listener serves HTTPS, taking the certificates
+// from the config unless `CertFile` and `KeyFile` are set.
+// - `Listener`: Optional pre-bound listener served instead of binding `Network` and `Addr`.
+//
+// **Example:**
+// ```go
+//
+//	err := engine.RunMulti([]ListenConfig{
+//	    {Addr: ":8080"},
+//	    {Addr: ":8443", CertFile: "server.crt", KeyFile: "server.key"},
+//	})
+//
+// ```
+type ListenConfig struct {
+	Network   string
+	Addr      string
+	CertFile  string
+	KeyFile   string
+	TLSConfig *tls.Config
+	Listener  net.Listener
+}
+
+// network returns the configured network, defaulting to "tcp".
+func (lc ListenConfig) network() string {
+	if lc.Network == "" {
+		return "tcp"
+	}
+
+	return lc.Network
+}
+
+// isTLS reports whether the listener should serve HTTPS.
+func (lc ListenConfig) isTLS() bool {
+	return lc.CertFile != "" || lc.KeyFile != "" || lc.TLSConfig != nil
+}
+
+// Server is an additional server started and stopped together with the engine listeners,
+// e.g. an HTTP/3 server provided by an integration package. Register it with WithServers.
+type Server interface {
+	// Serve blocks serving the given handler until the server fails or Shutdown is called.
+	// Returning http.ErrServerClosed after Shutdown is not treated as an error.
+	Serve(handler http.Handler) error
+
+	// Shutdown gracefully stops the server within the deadline of ctx.
+	Shutdown(ctx context.Context) error
+}
+
+// listener couples an opened net.Listener with the http.Server that serves it.
+type listener struct {
+	config ListenConfig
+	ln     net.Listener
+	srv    *http.Server
+}
+
+// serve blocks serving requests on the listener. http.ErrServerClosed is not reported as an error.
+func (l *listener) serve() error {
+	var err error
+	if l.config.isTLS() {
+		err = l.srv.ServeTLS(l.ln, l.config.CertFile, l.config.KeyFile)
+	} else {
+		err = l.srv.Serve(l.ln)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("listener %s failed: %w", l.config.Addr, err)
+	}
+
+	return nil
+}
+
+// openListeners validates the given configs and binds every address before any of them starts serving,
+// so that a port collision is reported before the engine accepts traffic. Duplicated addresses within
+// the configs are rejected with ErrDuplicateListenAddr; addresses already taken by another process
+// surface as the bind error returned by the operating system. On failure all listeners opened so far are closed.
+// When WithMaxConnections was provided, every listener is limited to that many simultaneous connections,
+// and the servers are tuned by the functions passed to WithHTTPServer. With WithH2C, cleartext listeners also serve h2c.
+func (c *core) openListeners(configs []ListenConfig, handler http.Handler) ([]*listener, error) {
+	if len(configs) == 0 {
+		return nil, ErrNoListeners
+	}
+
+	configs = slices.Clone(configs)
+	for i, config := range configs {
+		if config.Listener != nil && config.Addr == "" {
+			configs[i].Addr = config.Listener.Addr().String()
+		}
+	}
+
+	seen := make(map[string]struct{}, len(configs))
+	for _, config := range configs {
+		if _, ok := seen[config.Addr]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateListenAddr, config.Addr)
+		}
+		seen[config.Addr] = struct{}{}
+	}
+
+	listeners := make([]*listener, 0, len(configs))
+	for _, config := range configs {
+		ln, err := listen(config)
+		if err != nil {
+			closeListeners(listeners)
+			return nil, err
+		}
+
+		if c.maxConnections > 0 {
+			ln = netutil.LimitListener(ln, c.maxConnections)
+		}
+
+		srv := &http.Server{
+			Addr:      config.Addr,
+			TLSConfig: config.TLSConfig,
+		}
+
+		for _, configure := range c.httpServerConfigurers {
+			configure(srv)
+		}
+
+		// The handler is set after the configurers so requests always go through the engine.
+		srv.Handler = handler
+		if c.h2c && !config.isTLS() {
+			srv.Handler, err = h2cHandler(srv, handler)
+			if err != nil {
+				closeListeners(append(listeners, &listener{config: config, ln: ln, srv: srv}))
+				return nil, err
+			}
+		}
+
+		listeners = append(listeners, &listener{
+			config: config,
+			ln:     ln,
+			srv:    srv,
+		})
+	}
+
+	return listeners, nil
+}
+
+// listen returns the pre-bound listener of config, or binds its address.
+func listen(config ListenConfig) (net.Listener, error) {
+	if config.Listener != nil {
+		return config.Listener, nil
+	}
+
+	if config.network() == "unix" {
+		if err := removeStaleSocket(config.Addr); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen(config.network(), config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", config.Addr, err)
+	}
+
+	return ln, nil
+}
+
+// Addr returns the address of the first listener once Run or RunMulti has bound it, and nil before that or
+// once the servers are shut down. It reports the port chosen by the system when listening on port 0, e.g. ":0".
+// As Run blocks, Addr is meant to be polled from another goroutine; pass a bound listener via WithListener
+// to know the address upfront instead.
+func (c *core) Addr() net.Addr {
+	c.addrsMu.RLock()
+	defer c.addrsMu.RUnlock()
+
+	if len(c.addrs) == 0 {
+		return nil
+	}
+
+	return c.addrs[0]
+}
+
+// setListenerAddrs records the addresses of the listeners served by RunMulti, see Addr.
+func (c *core) setListenerAddrs(listeners []*listener) {
+	addrs := make([]net.Addr, 0, len(listeners))
+	for _, l := range listeners {
+		addrs = append(addrs, l.ln.Addr())
+	}
+
+	c.addrsMu.Lock()
+	c.addrs = addrs
+	c.addrsMu.Unlock()
+}
+
+func closeListeners(listeners []*listener) {
+	for _, l := range listeners {
+		_ = l.ln.Close()
+		l.cleanup()
+	}
+}
+
+// cleanup removes the socket file of a Unix listener once it has been closed.
+func (l *listener) cleanup() {
+	if l.config.network() == "unix" {
+		_ = os.Remove(l.config.Addr)
+	}
+}
+
+// removeStaleSocket removes a socket file left behind by a previous process. The socket is dialed first and only
+// removed when the connection is refused; ErrSocketInUse is returned when another process still accepts on it.
+// Paths that exist but are not sockets are never removed and ErrNotASocket is returned instead.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to stat socket %s: %w", path, err)
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%w: %s", ErrNotASocket, path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		_ = conn.Close()
+
+		return fmt.Errorf("%w: %s", ErrSocketInUse, path)
+	} else if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("failed to probe socket %s: %w", path, err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+	}
+
+	return nil
+}
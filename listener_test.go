@@ -0,0 +1,78 @@
+package httpbara
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveStaleSocket(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, path string)
+		err     error
+		removed bool
+	}{
+		{
+			name:    "missing path",
+			prepare: func(t *testing.T, path string) {},
+			removed: true,
+		},
+		{
+			name: "stale socket",
+			prepare: func(t *testing.T, path string) {
+				ln, err := net.Listen("unix", path)
+				if err != nil {
+					t.Fatalf("failed to listen: %v", err)
+				}
+
+				// Keep the file when closing, as a crashed process would
+				ln.(*net.UnixListener).SetUnlinkOnClose(false)
+				_ = ln.Close()
+			},
+			removed: true,
+		},
+		{
+			name: "socket in use",
+			prepare: func(t *testing.T, path string) {
+				ln, err := net.Listen("unix", path)
+				if err != nil {
+					t.Fatalf("failed to listen: %v", err)
+				}
+
+				t.Cleanup(func() {
+					_ = ln.Close()
+				})
+			},
+			err: ErrSocketInUse,
+		},
+		{
+			name: "regular file",
+			prepare: func(t *testing.T, path string) {
+				if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+					t.Fatalf("failed to write file: %v", err)
+				}
+			},
+			err: ErrNotASocket,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "httpbara.sock")
+			tt.prepare(t, path)
+
+			err := removeStaleSocket(path)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+
+			_, statErr := os.Lstat(path)
+			if removed := errors.Is(statErr, os.ErrNotExist); removed != tt.removed {
+				t.Fatalf("removed = %v, want %v", removed, tt.removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package httpbara_test
+
+import (
+	"context"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"testing"
+)
+
+type createdProduct struct {
+	ID string `json:"id"`
+}
+
+// Location dereferences the product, as most implementations do.
+func (p *createdProduct) Location() string {
+	return "/products/" + p.ID
+}
+
+type createProductRequest struct {
+	ID string `json:"id" form:"id"`
+}
+
+type locationHandlerDescriber struct {
+	Create httpbara.Route `route:"POST /products" status:"201"`
+}
+
+type locationHandler struct {
+	locationHandlerDescriber
+}
+
+func (h *locationHandler) Create(ctx context.Context, req *createProductRequest) (*createdProduct, error) {
+	if req.ID == "" {
+		return nil, nil
+	}
+
+	return &createdProduct{ID: req.ID}, nil
+}
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []httpbara.ParamsCb
+		target   string
+		status   int
+		location string
+	}{
+		{name: "created resource", target: "/products?id=42", status: http.StatusCreated, location: "/products/42"},
+		{name: "nil resource", target: "/products", status: http.StatusCreated},
+		{
+			name:   "nil resource with empty status 200",
+			opts:   []httpbara.ParamsCb{httpbara.WithEmptyResponseStatus(http.StatusOK)},
+			target: "/products",
+			status: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &locationHandler{})}, tt.opts...)
+
+			rec := serve(h, http.MethodPost, tt.target, nil, nil)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if location := rec.Header().Get("Location"); location != tt.location {
+				t.Fatalf("Location = %q, want %q", location, tt.location)
+			}
+		})
+	}
+}
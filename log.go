@@ -6,6 +6,11 @@ import (
 	"time"
 )
 
+// Logger is the structured logger used by the engine. The args are alternating key-value pairs.
+//
+// Panic logs a message about a condition the engine cannot handle, such as a casual handler with an unsupported
+// signature. Implementations may panic after logging, but are not required to. Request dispatch does not call
+// Panic: conditions found while serving a request are logged with Error and answered with a 500 envelope.
 type Logger interface {
 	Info(message string, args ...any)
 	Debug(message string, args ...any)
@@ -14,8 +19,20 @@ type Logger interface {
 	Warn(message string, args ...any)
 }
 
+// FmtLoggerOpt configures the logger created by NewFmtLogger.
+type FmtLoggerOpt func(*fmtLogger)
+
+// WithPanicLogOnly makes Panic log the message at the PANIC level and return instead of panicking.
+func WithPanicLogOnly() FmtLoggerOpt {
+	return func(l *fmtLogger) {
+		l.panicLogOnly = true
+	}
+}
+
 type fmtLogger struct {
 	Logger
+
+	panicLogOnly bool
 }
 
 func (l *fmtLogger) mapFields(fields ...any) string {
@@ -60,6 +77,10 @@ func (l *fmtLogger) Error(message string, args ...any) {
 
 func (l *fmtLogger) Panic(message string, args ...any) {
 	l.log("PANIC", message, args...)
+	if l.panicLogOnly {
+		return
+	}
+
 	panic(message)
 }
 
@@ -67,6 +88,84 @@ func (l *fmtLogger) Warn(message string, args ...any) {
 	l.log("WARN", message, args...)
 }
 
-func NewFmtLogger() Logger {
-	return &fmtLogger{}
+// NewFmtLogger creates a Logger printing to stdout. By default Panic panics after logging, see WithPanicLogOnly.
+func NewFmtLogger(opts ...FmtLoggerOpt) Logger {
+	l := &fmtLogger{}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+type multiLogger struct {
+	loggers []Logger
+}
+
+func (l *multiLogger) Info(message string, args ...any) {
+	for _, logger := range l.loggers {
+		logger.Info(message, args...)
+	}
+}
+
+func (l *multiLogger) Debug(message string, args ...any) {
+	for _, logger := range l.loggers {
+		logger.Debug(message, args...)
+	}
+}
+
+func (l *multiLogger) Error(message string, args ...any) {
+	for _, logger := range l.loggers {
+		logger.Error(message, args...)
+	}
+}
+
+// Panic calls Panic on every logger. A logger panicking does not stop the others from writing: the first panic
+// is raised again once all of them were called.
+func (l *multiLogger) Panic(message string, args ...any) {
+	var recovered any
+
+	for _, logger := range l.loggers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil && recovered == nil {
+					recovered = r
+				}
+			}()
+
+			logger.Panic(message, args...)
+		}()
+	}
+
+	if recovered != nil {
+		panic(recovered)
+	}
+}
+
+func (l *multiLogger) Warn(message string, args ...any) {
+	for _, logger := range l.loggers {
+		logger.Warn(message, args...)
+	}
+}
+
+// MultiLogger creates a Logger writing every record to all the given loggers, in order, e.g. to stdout and to a
+// file at the same time. Nil loggers are skipped. Panic is called on every logger and the first panic
+// is raised again only once all of them have written.
+//
+// **Example:**
+// ```go
+//
+//	log := httpbara.MultiLogger(httpbara.NewFmtLogger(), httpbarazap.New(fileLogger))
+//	engine, _ := httpbara.New(handlers, httpbara.WithLogger(log))
+//
+// ```
+func MultiLogger(loggers ...Logger) Logger {
+	l := &multiLogger{loggers: make([]Logger, 0, len(loggers))}
+	for _, logger := range loggers {
+		if logger != nil {
+			l.loggers = append(l.loggers, logger)
+		}
+	}
+
+	return l
 }
@@ -1,10 +1,125 @@
 package httpbara
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// RedactedLogValue replaces redacted header values and JSON body fields in the access log.
+const RedactedLogValue = "***"
+
+const logFieldsKey = "fields"
+
+// logFieldsContextKey is the request context key of the additional access log fields.
+type logFieldsContextKey struct{}
+
+type accessLogOpts struct {
+	headers         []string
+	redactedHeaders []string
+	logBody         bool
+	maxBodySize     int64
+	redactedFields  [][]string
+	redactedQuery   []string
+	requestSize     bool
+	responseSize    bool
+	remoteIP        bool
+	userAgent       bool
+	sampleEvery     uint64
+}
+
+type AccessLogOpt func(*accessLogOpts)
+
+// WithAccessLogHeaders logs the values of the given request headers under the "headers" field.
+func WithAccessLogHeaders(headers ...string) AccessLogOpt {
+	return func(opts *accessLogOpts) {
+		opts.headers = append(opts.headers, headers...)
+	}
+}
+
+// WithAccessLogRedactedHeaders replaces the values of the given headers with RedactedLogValue
+// (Authorization, Proxy-Authorization and Cookie are redacted by default).
+func WithAccessLogRedactedHeaders(headers ...string) AccessLogOpt {
+	return func(opts *accessLogOpts) {
+		opts.redactedHeaders = append(opts.redactedHeaders, headers...)
+	}
+}
+
+// WithAccessLogBody logs JSON request bodies of up to maxSize bytes under the "body" field.
+// The body is buffered and restored, so handlers read it as usual.
+func WithAccessLogBody(maxSize int64) AccessLogOpt {
+	return func(opts *accessLogOpts) {
+		opts.logBody = true
+		opts.maxBodySize = maxSize
+	}
+}
+
+// WithAccessLogRedactedFields replaces the values of the given JSON body fields with RedactedLogValue.
+// Fields are dot-separated paths from the root object, e.g. "password" or "user.credentials.token";
+// paths crossing arrays are applied to every element.
+func WithAccessLogRedactedFields(paths ...string) AccessLogOpt {
+	return func(opts *accessLogOpts) {
+		for _, path := range paths {
+			opts.redactedFields = append(opts.redactedFields, strings.Split(path, "."))
+		}
+	}
+}
+
+// WithAccessLogRedactedQuery replaces the values of the given query parameters with RedactedLogValue
+// in the "query" field, e.g. "token".
+func WithAccessLogRedactedQuery(params ...string) AccessLogOpt {
+	return func(opts *accessLogOpts) {
+		opts.redactedQuery = append(opts.redactedQuery, params...)
+	}
+}
+
+// WithAccessLogRequestSize logs the size of the request body as declared by its Content-Length
+// under the "requestSize" field, when known.
+func WithAccessLogRequestSize() AccessLogOpt {
+	return func(opts *accessLogOpts) {
+		opts.requestSize = true
+	}
+}
+
+// WithAccessLogResponseSize logs the number of response body bytes written under the "responseSize" field.
+func WithAccessLogResponseSize() AccessLogOpt {
+	return func(opts *accessLogOpts) {
+		opts.responseSize = true
+	}
+}
+
+// WithAccessLogRemoteIP logs the client IP as resolved by gin.Context.ClientIP under the "remoteIp" field.
+func WithAccessLogRemoteIP() AccessLogOpt {
+	return func(opts *accessLogOpts) {
+		opts.remoteIP = true
+	}
+}
+
+// WithAccessLogUserAgent logs the User-Agent request header under the "userAgent" field.
+func WithAccessLogUserAgent() AccessLogOpt {
+	return func(opts *accessLogOpts) {
+		opts.userAgent = true
+	}
+}
+
+// WithAccessLogSampling logs only one in every n requests, e.g. for high-traffic routes. Requests answered
+// with a 5xx status are always logged. Values below 2 log every request.
+func WithAccessLogSampling(n int) AccessLogOpt {
+	return func(opts *accessLogOpts) {
+		opts.sampleEvery = 0
+		if n > 1 {
+			opts.sampleEvery = uint64(n)
+		}
+	}
+}
+
 type accessLogMiddlewareDescriber struct {
 	AccessLogMiddleware Middleware `middleware:"log"`
 }
@@ -12,7 +127,10 @@ type accessLogMiddlewareDescriber struct {
 type accessLogMiddleware struct {
 	accessLogMiddlewareDescriber
 
-	log Logger
+	log  Logger
+	opts accessLogOpts
+
+	requests atomic.Uint64
 }
 
 func (alm *accessLogMiddleware) AccessLogMiddleware(ctx *gin.Context) {
@@ -23,34 +141,243 @@ func (alm *accessLogMiddleware) AccessLogMiddleware(ctx *gin.Context) {
 	}
 	var additionalFields []interface{}
 
-	ctx.Set("fields", &additionalFields)
+	if headers := alm.headers(ctx.Request.Header); len(headers) > 0 {
+		fields = append(fields, "headers", headers)
+	}
+
+	if body, ok := alm.body(ctx); ok {
+		fields = append(fields, "body", body)
+	}
+
+	ctx.Set(logFieldsKey, &additionalFields)
+	ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), logFieldsContextKey{}, &additionalFields))
 
 	ctx.Next()
 
+	if !alm.sampled(ctx.Writer.Status()) {
+		return
+	}
+
 	fields = append(fields, "status", ctx.Writer.Status())
-	if len(ctx.Request.URL.Query()) > 0 {
-		fields = append(fields, "query", ctx.Request.URL.Query())
+	if query := alm.query(ctx.Request.URL.Query()); len(query) > 0 {
+		fields = append(fields, "query", query)
 	}
 
 	fields = append(fields, "duration", time.Since(ts))
 
+	if id := ctx.GetString(requestIDKey); id != "" {
+		fields = append(fields, "requestId", id)
+	}
+
+	if alm.opts.requestSize && ctx.Request.ContentLength >= 0 {
+		fields = append(fields, "requestSize", ctx.Request.ContentLength)
+	}
+
+	if alm.opts.responseSize {
+		fields = append(fields, "responseSize", max(ctx.Writer.Size(), 0))
+	}
+
+	if alm.opts.remoteIP {
+		fields = append(fields, "remoteIp", ctx.ClientIP())
+	}
+
+	if alm.opts.userAgent {
+		fields = append(fields, "userAgent", ctx.Request.UserAgent())
+	}
+
 	alm.log.Info("request done", append(fields, additionalFields...)...)
 }
 
+// sampled reports whether the request answered with status is logged, see WithAccessLogSampling.
+func (alm *accessLogMiddleware) sampled(status int) bool {
+	if alm.opts.sampleEvery == 0 || status >= http.StatusInternalServerError {
+		return true
+	}
+
+	return (alm.requests.Add(1)-1)%alm.opts.sampleEvery == 0
+}
+
+// query returns the query parameters of the request with redacted values replaced.
+func (alm *accessLogMiddleware) query(query url.Values) url.Values {
+	for _, name := range alm.opts.redactedQuery {
+		if values, ok := query[name]; ok {
+			for i := range values {
+				values[i] = RedactedLogValue
+			}
+		}
+	}
+
+	return query
+}
+
+// headers returns the logged request headers with redacted values replaced.
+func (alm *accessLogMiddleware) headers(header http.Header) map[string]string {
+	result := make(map[string]string)
+
+	for _, name := range alm.opts.headers {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+
+		for _, redacted := range alm.opts.redactedHeaders {
+			if strings.EqualFold(name, redacted) {
+				value = RedactedLogValue
+				break
+			}
+		}
+
+		result[http.CanonicalHeaderKey(name)] = value
+	}
+
+	return result
+}
+
+// body buffers a JSON request body, restores it for the handler and returns it with redacted fields replaced.
+func (alm *accessLogMiddleware) body(ctx *gin.Context) (string, bool) {
+	if !alm.opts.logBody || ctx.Request.Body == nil || !strings.HasSuffix(ctx.ContentType(), "json") {
+		return "", false
+	}
+
+	if ctx.Request.ContentLength > alm.opts.maxBodySize {
+		return "", false
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(ctx.Request.Body, alm.opts.maxBodySize+1))
+	ctx.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.MultiReader(bytes.NewReader(raw), &errReader{err: err, r: ctx.Request.Body}),
+		Closer: ctx.Request.Body,
+	}
+
+	if err != nil || int64(len(raw)) > alm.opts.maxBodySize {
+		return "", false
+	}
+
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", false
+	}
+
+	for _, path := range alm.opts.redactedFields {
+		redactJSONPath(value, path)
+	}
+
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+
+	return string(redacted), true
+}
+
+// redactJSONPath replaces the value at path inside a decoded JSON value with RedactedLogValue.
+func redactJSONPath(value any, path []string) {
+	switch v := value.(type) {
+	case map[string]any:
+		if _, ok := v[path[0]]; !ok {
+			return
+		}
+
+		if len(path) == 1 {
+			v[path[0]] = RedactedLogValue
+			return
+		}
+
+		redactJSONPath(v[path[0]], path[1:])
+	case []any:
+		for _, item := range v {
+			redactJSONPath(item, path)
+		}
+	}
+}
+
+// AddLogFieldToAccessLog appends key-value pairs to the access log entry of the request.
+// It does nothing when the access log middleware was not applied.
 func AddLogFieldToAccessLog(ctx *gin.Context, value ...interface{}) {
-	fields, ok := ctx.Get("fields")
-	if !ok {
-		fields = []interface{}{}
+	if logFields := accessLogFields(ctx); logFields != nil {
+		*logFields = append(*logFields, value...)
 	}
+}
 
-	logFields := fields.(*[]interface{})
+// WithField adds a field to the access log entry of the request from a request context or a *gin.Context,
+// so fields can be added deep inside a handler without passing the gin context around.
+// It does nothing when the access log middleware was not applied.
+//
+// **Example:**
+// ```go
+//
+//	func (s *Service) Charge(ctx context.Context, orderID string) error {
+//	    httpbara.WithField(ctx, "orderId", orderID)
+//	    ...
+//	}
+//
+// ```
+func WithField(ctx context.Context, key string, value any) {
+	if logFields := accessLogFields(ctx); logFields != nil {
+		*logFields = append(*logFields, key, value)
+	}
+}
+
+// LogFields returns a copy of the key-value pairs added to the access log entry of the request so far,
+// from a request context or a *gin.Context, e.g. to attach them to other log records of the request.
+func LogFields(ctx context.Context) []any {
+	logFields := accessLogFields(ctx)
+	if logFields == nil {
+		return nil
+	}
+
+	fields := make([]any, len(*logFields))
+	copy(fields, *logFields)
+
+	return fields
+}
 
-	*logFields = append(*logFields, value...)
+// accessLogFields returns the additional fields stored by the access log middleware, or nil.
+func accessLogFields(ctx context.Context) *[]interface{} {
+	if gctx, ok := ctx.(*gin.Context); ok {
+		fields, _ := gctx.Get(logFieldsKey)
+		logFields, _ := fields.(*[]interface{})
+
+		return logFields
+	}
+
+	logFields, _ := ctx.Value(logFieldsContextKey{}).(*[]interface{})
+
+	return logFields
 }
 
-func NewAccessLogMiddleware(log Logger) (*Handler, error) {
+// NewAccessLogMiddleware creates a middleware named "log" that logs every request once it is handled with its
+// method, path, status, query and duration. Request headers, JSON bodies, sizes, the remote IP and the user agent
+// are only logged when enabled through the options, with sensitive headers, body fields and query parameters
+// replaced by RedactedLogValue, and requests can be sampled. The ID set by the request ID middleware is logged
+// as the "requestId" field, and fields added via AddLogFieldToAccessLog or WithField are appended.
+//
+// **Example:**
+// ```go
+//
+//	accessLog, _ := httpbara.NewAccessLogMiddleware(log,
+//	    httpbara.WithAccessLogHeaders("Authorization", "User-Agent"),
+//	    httpbara.WithAccessLogBody(64<<10),
+//	    httpbara.WithAccessLogRedactedFields("password", "card.number"),
+//	    httpbara.WithAccessLogRedactedQuery("token"),
+//	    httpbara.WithAccessLogRemoteIP(),
+//	    httpbara.WithAccessLogSampling(10),
+//	)
+//
+// ```
+func NewAccessLogMiddleware(log Logger, opts ...AccessLogOpt) (*Handler, error) {
 	alm := accessLogMiddleware{
 		log: log,
+		opts: accessLogOpts{
+			redactedHeaders: []string{"Authorization", "Proxy-Authorization", "Cookie"},
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&alm.opts)
 	}
 
 	return AsHandler(&alm)
@@ -0,0 +1,110 @@
+package httpbara_test
+
+import (
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// captureLogger is a httpbara.Logger keeping the fields of every Info message.
+type captureLogger struct {
+	discardLogger
+
+	entries []map[string]any
+}
+
+func (l *captureLogger) Info(msg string, args ...any) {
+	entry := map[string]any{"msg": msg}
+	for i := 0; i+1 < len(args); i += 2 {
+		entry[args[i].(string)] = args[i+1]
+	}
+
+	l.entries = append(l.entries, entry)
+}
+
+func TestAccessLogRedaction(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []httpbara.AccessLogOpt
+		target  string
+		body    string
+		headers map[string]string
+		field   string
+		want    any
+	}{
+		{
+			name:    "default redacted headers",
+			opts:    []httpbara.AccessLogOpt{httpbara.WithAccessLogHeaders("Authorization", "Cookie", "X-Tenant")},
+			target:  "/echo",
+			headers: map[string]string{"Authorization": "Bearer secret", "Cookie": "session=secret", "X-Tenant": "acme"},
+			field:   "headers",
+			want: map[string]string{
+				"Authorization": httpbara.RedactedLogValue,
+				"Cookie":        httpbara.RedactedLogValue,
+				"X-Tenant":      "acme",
+			},
+		},
+		{
+			name: "custom redacted headers",
+			opts: []httpbara.AccessLogOpt{
+				httpbara.WithAccessLogHeaders("x-api-key"),
+				httpbara.WithAccessLogRedactedHeaders("X-Api-Key"),
+			},
+			target:  "/echo",
+			headers: map[string]string{"X-Api-Key": "secret"},
+			field:   "headers",
+			want:    map[string]string{"X-Api-Key": httpbara.RedactedLogValue},
+		},
+		{
+			name: "body fields",
+			opts: []httpbara.AccessLogOpt{
+				httpbara.WithAccessLogBody(1 << 10),
+				httpbara.WithAccessLogRedactedFields("password", "card.number", "tokens.value", "missing.field"),
+			},
+			target: "/echo",
+			body:   `{"login":"bara","password":"secret","card":{"number":"4242","exp":"12/30"},"tokens":[{"value":"a"},{"value":"b"}]}`,
+			field:  "body",
+			want:   `{"card":{"exp":"12/30","number":"***"},"login":"bara","password":"***","tokens":[{"value":"***"},{"value":"***"}]}`,
+		},
+		{
+			name:   "query parameters",
+			opts:   []httpbara.AccessLogOpt{httpbara.WithAccessLogRedactedQuery("token")},
+			target: "/echo?token=secret&page=2",
+			field:  "query",
+			want:   url.Values{"token": {httpbara.RedactedLogValue}, "page": {"2"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &captureLogger{}
+			accessLog, err := httpbara.NewAccessLogMiddleware(log, tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to create middleware: %v", err)
+			}
+
+			h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &echoHandler{})}, httpbara.WithRootMiddlewares(accessLog))
+
+			headers := map[string]string{"Content-Type": "application/json"}
+			for key, value := range tt.headers {
+				headers[key] = value
+			}
+
+			rec := serve(h, http.MethodPost, tt.target, strings.NewReader(tt.body), headers)
+			if rec.Code != http.StatusOK || rec.Body.String() != tt.body {
+				t.Fatalf("response = %d %q, want the unredacted body %q", rec.Code, rec.Body.String(), tt.body)
+			}
+
+			if len(log.entries) != 1 {
+				t.Fatalf("logged %d entries, want 1", len(log.entries))
+			}
+
+			if got := log.entries[0][tt.field]; !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("%s = %#v, want %#v", tt.field, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package httpbara
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	// ErrInvalidMarker is returned when a value registered as a marker type is not a struct.
+	ErrInvalidMarker = errors.New("marker type must be a struct")
+
+	markersMu         sync.RWMutex
+	routeMarkers      = map[reflect.Type]struct{}{reflect.TypeOf(Route{}): {}}
+	groupMarkers      = map[reflect.Type]struct{}{reflect.TypeOf(Group{}): {}}
+	middlewareMarkers = map[reflect.Type]struct{}{reflect.TypeOf(Middleware{}): {}}
+)
+
+// RegisterRouteMarker registers the type of marker as an additional field type that AsHandler treats like `Route`.
+// It lets wrapper libraries declare routes with their own describer types, e.g. `type Endpoint httpbara.Route`.
+//
+// The reflection contract for marker types, shared by RegisterGroupMarker and RegisterMiddlewareMarker:
+// - The marker must be a struct type. Pointer fields (`*Endpoint`) are not recognized, only value fields.
+// - A field of the marker type is read through the same tags as the built-in type (`route`, `group`, `middlewares`, ...).
+// - Routes and middlewares need a method with the same name as the field on the handler struct, following
+// the `func(*gin.Context)` signature or the casual handler signature for routes.
+// - Markers should be registered before AsHandler is called, usually from an `init` function.
+//
+// **Example:**
+// ```go
+//
+//	type Endpoint httpbara.Route
+//
+//	func init() {
+//	    _ = httpbara.RegisterRouteMarker(Endpoint{})
+//	}
+//
+//	type UsersDescriber struct {
+//	    GetUser Endpoint `route:"GET /users/:id"`
+//	}
+//
+// ```
+func RegisterRouteMarker(marker any) error {
+	return registerMarker(routeMarkers, marker)
+}
+
+// RegisterGroupMarker registers the type of marker as an additional field type that AsHandler treats like `Group`.
+// See RegisterRouteMarker for the contract marker types must satisfy.
+func RegisterGroupMarker(marker any) error {
+	return registerMarker(groupMarkers, marker)
+}
+
+// RegisterMiddlewareMarker registers the type of marker as an additional field type that AsHandler treats like `Middleware`.
+// See RegisterRouteMarker for the contract marker types must satisfy.
+func RegisterMiddlewareMarker(marker any) error {
+	return registerMarker(middlewareMarkers, marker)
+}
+
+func registerMarker(markers map[reflect.Type]struct{}, marker any) error {
+	t := reflect.TypeOf(marker)
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: got %v", ErrInvalidMarker, t)
+	}
+
+	markersMu.Lock()
+	defer markersMu.Unlock()
+
+	markers[t] = struct{}{}
+
+	return nil
+}
+
+func isMarker(markers map[reflect.Type]struct{}, t reflect.Type) bool {
+	markersMu.RLock()
+	defer markersMu.RUnlock()
+
+	_, ok := markers[t]
+
+	return ok
+}
@@ -0,0 +1,126 @@
+package httpbara_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type blockingHandlerDescriber struct {
+	Block httpbara.Route `route:"GET /block"`
+}
+
+type blockingHandler struct {
+	blockingHandlerDescriber
+
+	active  atomic.Int32
+	release chan struct{}
+}
+
+func (h *blockingHandler) Block(ctx *gin.Context) {
+	h.active.Add(1)
+	<-h.release
+	ctx.String(http.StatusOK, "ok")
+}
+
+func TestWithMaxConnections(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		conns int
+	}{
+		{name: "one connection", limit: 1, conns: 3},
+		{name: "two connections", limit: 2, conns: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &blockingHandler{release: make(chan struct{})}
+
+			engine, err := httpbara.New([]*httpbara.Handler{mustHandler(t, handler)},
+				httpbara.WithLogger(discardLogger{}),
+				httpbara.WithMaxConnections(tt.limit),
+			)
+			if err != nil {
+				t.Fatalf("failed to create engine: %v", err)
+			}
+
+			if err := engine.Start("127.0.0.1:0"); err != nil {
+				t.Fatalf("failed to start engine: %v", err)
+			}
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				_ = engine.Stop(ctx)
+			}()
+
+			// Every connection sends a request right away, the server only accepts limit of them
+			responses := make(chan error, tt.conns)
+			for range tt.conns {
+				conn, err := net.Dial("tcp", engine.Addr().String())
+				if err != nil {
+					t.Fatalf("failed to dial: %v", err)
+				}
+				defer conn.Close()
+
+				go func() {
+					_, err := fmt.Fprint(conn, "GET /block HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n")
+					if err == nil {
+						var resp *http.Response
+						if resp, err = http.ReadResponse(bufio.NewReader(conn), nil); err == nil {
+							resp.Body.Close()
+						}
+					}
+
+					responses <- err
+				}()
+			}
+
+			waitFor(t, func() bool { return handler.active.Load() == int32(tt.limit) })
+
+			time.Sleep(100 * time.Millisecond)
+			if active := handler.active.Load(); active != int32(tt.limit) {
+				t.Fatalf("active requests = %d, want %d", active, tt.limit)
+			}
+
+			// Closed connections free their slot, so the waiting ones are served in turn
+			close(handler.release)
+			for range tt.conns {
+				select {
+				case err := <-responses:
+					if err != nil {
+						t.Fatalf("request failed: %v", err)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for the waiting connections to be served")
+				}
+			}
+
+			if active := handler.active.Load(); active != int32(tt.conns) {
+				t.Fatalf("served requests = %d, want %d", active, tt.conns)
+			}
+		})
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
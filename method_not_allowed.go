@@ -0,0 +1,37 @@
+package httpbara
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+)
+
+var (
+	// ErrMethodNotAllowed is rendered through the casual error responder with 405 when a path is registered
+	// but not for the method of the request. See WithMethodNotAllowedHandler.
+	ErrMethodNotAllowed = casual.NewHTTPErrorFromMessage(http.StatusMethodNotAllowed, "method not allowed")
+)
+
+// methodNotAllowedHandler renders ErrMethodNotAllowed. Gin has already set the `Allow` header to the methods
+// registered for the path when the handler runs.
+func (c *core) methodNotAllowedHandler(ctx *gin.Context) {
+	rcb := c.getResponseCallback(ctx)
+	rcb(c.casualResponseErrorHandler(ErrMethodNotAllowed, languageParams(ctx)...))
+	ctx.Abort()
+}
+
+// applyMethodNotAllowed makes the Gin engine answer requests whose path is registered for other methods only
+// with 405 instead of 404, when enabled via WithMethodNotAllowedHandler.
+func (c *core) applyMethodNotAllowed() {
+	if !c.methodNotAllowed {
+		return
+	}
+
+	handlers := c.methodNotAllowedHandlers
+	if len(handlers) == 0 {
+		handlers = []gin.HandlerFunc{c.methodNotAllowedHandler}
+	}
+
+	c.gin.HandleMethodNotAllowed = true
+	c.gin.NoMethod(handlers...)
+}
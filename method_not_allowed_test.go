@@ -0,0 +1,131 @@
+package httpbara_test
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type methodsHandlerDescriber struct {
+	List   httpbara.Route `route:"GET /items"`
+	Create httpbara.Route `route:"POST /items"`
+	Delete httpbara.Route `route:"DELETE /items/:id"`
+}
+
+type methodsHandler struct {
+	methodsHandlerDescriber
+}
+
+func (h *methodsHandler) List(ctx *gin.Context) {
+	ctx.String(http.StatusOK, "list")
+}
+
+func (h *methodsHandler) Create(ctx *gin.Context) {
+	ctx.String(http.StatusCreated, "created")
+}
+
+func (h *methodsHandler) Delete(ctx *gin.Context) {
+	ctx.Status(http.StatusNoContent)
+}
+
+func TestWithMethodNotAllowedHandler(t *testing.T) {
+	custom := func(ctx *gin.Context) {
+		ctx.String(http.StatusMethodNotAllowed, "use "+ctx.Writer.Header().Get("Allow"))
+	}
+
+	tests := []struct {
+		name     string
+		opts     []httpbara.ParamsCb
+		method   string
+		target   string
+		status   int
+		allow    []string
+		wantBody string
+	}{
+		{
+			name:   "wrong method",
+			opts:   []httpbara.ParamsCb{httpbara.WithMethodNotAllowedHandler()},
+			method: http.MethodPut,
+			target: "/items",
+			status: http.StatusMethodNotAllowed,
+			allow:  []string{http.MethodGet, http.MethodPost},
+		},
+		{
+			name:   "path parameter",
+			opts:   []httpbara.ParamsCb{httpbara.WithMethodNotAllowedHandler()},
+			method: http.MethodGet,
+			target: "/items/42",
+			status: http.StatusMethodNotAllowed,
+			allow:  []string{http.MethodDelete},
+		},
+		{
+			name:   "unknown path",
+			opts:   []httpbara.ParamsCb{httpbara.WithMethodNotAllowedHandler()},
+			method: http.MethodPut,
+			target: "/orders",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "registered method",
+			opts:   []httpbara.ParamsCb{httpbara.WithMethodNotAllowedHandler()},
+			method: http.MethodPost,
+			target: "/items",
+			status: http.StatusCreated,
+		},
+		{
+			name:     "custom handler",
+			opts:     []httpbara.ParamsCb{httpbara.WithNoMethodHandler(custom)},
+			method:   http.MethodPatch,
+			target:   "/items",
+			status:   http.StatusMethodNotAllowed,
+			allow:    []string{http.MethodGet, http.MethodPost},
+			wantBody: "use ",
+		},
+		{name: "disabled", method: http.MethodPut, target: "/items", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &methodsHandler{})}, tt.opts...)
+
+			rec := serve(h, tt.method, tt.target, nil, map[string]string{"Accept": "application/json"})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.allow == nil {
+				return
+			}
+
+			allow := strings.Split(rec.Header().Get("Allow"), ", ")
+			sort.Strings(allow)
+			sort.Strings(tt.allow)
+			if strings.Join(allow, ", ") != strings.Join(tt.allow, ", ") {
+				t.Fatalf("Allow = %q, want %q", rec.Header().Get("Allow"), strings.Join(tt.allow, ", "))
+			}
+
+			if tt.wantBody != "" {
+				// The custom handler sees the Allow header already set
+				if want := tt.wantBody + rec.Header().Get("Allow"); rec.Body.String() != want {
+					t.Fatalf("body = %q, want %q", rec.Body.String(), want)
+				}
+
+				return
+			}
+
+			var resp casual.HttpErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if resp.Status != http.StatusMethodNotAllowed || resp.Error == nil || resp.Error.Message != "method not allowed" {
+				t.Fatalf("response = %s, want the method not allowed envelope", rec.Body.String())
+			}
+		})
+	}
+}
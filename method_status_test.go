@@ -0,0 +1,100 @@
+package httpbara_test
+
+import (
+	"context"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"testing"
+)
+
+type acceptedItem struct {
+	ID string `json:"id"`
+}
+
+func (i *acceptedItem) StatusCode() int {
+	return http.StatusAccepted
+}
+
+type methodStatusItem struct {
+	ID string `json:"id"`
+}
+
+type methodStatusHandlerDescriber struct {
+	Get     httpbara.Route `route:"GET /items"`
+	Create  httpbara.Route `route:"POST /items"`
+	Update  httpbara.Route `route:"PUT /items"`
+	Delete  httpbara.Route `route:"DELETE /items"`
+	Import  httpbara.Route `route:"POST /items/import" status:"200"`
+	Enqueue httpbara.Route `route:"POST /items/enqueue"`
+}
+
+type methodStatusHandler struct {
+	methodStatusHandlerDescriber
+}
+
+func (h *methodStatusHandler) Get(ctx context.Context) (*methodStatusItem, error) {
+	return &methodStatusItem{ID: "1"}, nil
+}
+
+func (h *methodStatusHandler) Create(ctx context.Context) (*methodStatusItem, error) {
+	return &methodStatusItem{ID: "1"}, nil
+}
+
+func (h *methodStatusHandler) Update(ctx context.Context) (*methodStatusItem, error) {
+	return &methodStatusItem{ID: "1"}, nil
+}
+
+func (h *methodStatusHandler) Delete(ctx context.Context) error {
+	return nil
+}
+
+func (h *methodStatusHandler) Import(ctx context.Context) (*methodStatusItem, error) {
+	return &methodStatusItem{ID: "1"}, nil
+}
+
+func (h *methodStatusHandler) Enqueue(ctx context.Context) (*acceptedItem, error) {
+	return &acceptedItem{ID: "1"}, nil
+}
+
+func TestWithMethodDefaultStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		status     int
+		withoutOpt int
+	}{
+		{name: "GET keeps 200", method: http.MethodGet, target: "/items", status: http.StatusOK, withoutOpt: http.StatusOK},
+		{name: "POST", method: http.MethodPost, target: "/items", status: http.StatusCreated, withoutOpt: http.StatusOK},
+		{name: "PUT lowercase key", method: http.MethodPut, target: "/items", status: http.StatusAccepted, withoutOpt: http.StatusOK},
+		{name: "DELETE", method: http.MethodDelete, target: "/items", status: http.StatusNoContent, withoutOpt: http.StatusNoContent},
+		{name: "status tag overrides", method: http.MethodPost, target: "/items/import", status: http.StatusOK, withoutOpt: http.StatusOK},
+		{
+			name:       "StatusCode method overrides",
+			method:     http.MethodPost,
+			target:     "/items/enqueue",
+			status:     http.StatusAccepted,
+			withoutOpt: http.StatusAccepted,
+		},
+	}
+
+	withOpt := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &methodStatusHandler{})},
+		httpbara.WithMethodDefaultStatus(map[string]int{
+			"POST":   http.StatusCreated,
+			"put":    http.StatusAccepted,
+			"DELETE": http.StatusNoContent,
+		}))
+	withoutOpt := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &methodStatusHandler{})})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := serve(withOpt, tt.method, tt.target, nil, nil); rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if rec := serve(withoutOpt, tt.method, tt.target, nil, nil); rec.Code != tt.withoutOpt {
+				t.Fatalf("status without option = %d, want %d: %s", rec.Code, tt.withoutOpt, rec.Body.String())
+			}
+		})
+	}
+}
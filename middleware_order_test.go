@@ -0,0 +1,87 @@
+package httpbara_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// record appends name to the middlewares called for the request.
+func record(ctx *gin.Context, name string) {
+	ctx.Set("order", append(ctx.GetStringSlice("order"), name))
+}
+
+type rootMiddlewaresDescriber struct {
+	RootB httpbara.Middleware `middleware:"rootB"`
+	RootA httpbara.Middleware `middleware:"rootA"`
+}
+
+type rootMiddlewares struct {
+	rootMiddlewaresDescriber
+}
+
+func (m *rootMiddlewares) RootB(ctx *gin.Context) { record(ctx, "rootB") }
+func (m *rootMiddlewares) RootA(ctx *gin.Context) { record(ctx, "rootA") }
+
+type orderHandlerDescriber struct {
+	API httpbara.Group `group:"/api" middlewares:"groupB,groupA"`
+	V1  httpbara.Group `group:"/v1" parent:"api" middlewares:"child"`
+
+	GroupA httpbara.Middleware `middleware:"groupA"`
+	GroupB httpbara.Middleware `middleware:"groupB"`
+	Child  httpbara.Middleware `middleware:"child"`
+	RouteA httpbara.Middleware `middleware:"routeA"`
+	RouteB httpbara.Middleware `middleware:"routeB"`
+
+	Nested httpbara.Route `route:"GET /nested" group:"v1" middlewares:"routeB,routeA"`
+	Top    httpbara.Route `route:"GET /top" group:"api" middlewares:"routeA"`
+	Plain  httpbara.Route `route:"GET /plain"`
+}
+
+type orderHandler struct {
+	orderHandlerDescriber
+}
+
+func (h *orderHandler) GroupA(ctx *gin.Context) { record(ctx, "groupA") }
+func (h *orderHandler) GroupB(ctx *gin.Context) { record(ctx, "groupB") }
+func (h *orderHandler) Child(ctx *gin.Context)  { record(ctx, "child") }
+func (h *orderHandler) RouteA(ctx *gin.Context) { record(ctx, "routeA") }
+func (h *orderHandler) RouteB(ctx *gin.Context) { record(ctx, "routeB") }
+
+func (h *orderHandler) Nested(ctx *gin.Context) { h.order(ctx) }
+func (h *orderHandler) Top(ctx *gin.Context)    { h.order(ctx) }
+func (h *orderHandler) Plain(ctx *gin.Context)  { h.order(ctx) }
+
+func (h *orderHandler) order(ctx *gin.Context) {
+	ctx.String(http.StatusOK, strings.Join(ctx.GetStringSlice("order"), ","))
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		order  string
+	}{
+		{name: "root, parent group, child group and route", target: "/api/v1/nested", order: "rootB,rootA,groupB,groupA,child,routeB,routeA"},
+		{name: "root, group and route", target: "/api/top", order: "rootB,rootA,groupB,groupA,routeA"},
+		{name: "root only", target: "/plain", order: "rootB,rootA"},
+	}
+
+	root := mustHandler(t, &rootMiddlewares{})
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &orderHandler{})}, httpbara.WithRootMiddlewares(root))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, tt.target, nil, nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+
+			if rec.Body.String() != tt.order {
+				t.Fatalf("order = %q, want %q", rec.Body.String(), tt.order)
+			}
+		})
+	}
+}
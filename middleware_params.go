@@ -0,0 +1,92 @@
+package httpbara
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"reflect"
+	"strings"
+)
+
+// ParameterizedMiddleware builds the handler of a middleware from the argument it is referenced with,
+// e.g. "30s" for `middlewares:"cache=30s"`. A reference without argument builds it from an empty argument.
+//
+// Middleware methods with the signature of ParameterizedMiddlewareFunc are recognized as parameterized:
+// ```go
+//
+//	Cache Middleware `middleware:"cache"`
+//
+//	func (h *Handler) Cache(arg string) (gin.HandlerFunc, error) {
+//	    ttl, err := time.ParseDuration(arg)
+//	    ...
+//	}
+//
+// ```
+//
+// The handler is built once per distinct argument while New registers the routes, and an error fails New.
+type ParameterizedMiddleware interface {
+	Middleware(arg string) (gin.HandlerFunc, error)
+}
+
+// ParameterizedMiddlewareFunc adapts a function to ParameterizedMiddleware.
+type ParameterizedMiddlewareFunc func(arg string) (gin.HandlerFunc, error)
+
+func (f ParameterizedMiddlewareFunc) Middleware(arg string) (gin.HandlerFunc, error) {
+	return f(arg)
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// isParameterizedMiddleware reports whether t is a method with the signature of ParameterizedMiddlewareFunc.
+func isParameterizedMiddleware(t reflect.Type) bool {
+	return t.NumIn() == 2 && t.In(1).Kind() == reflect.String &&
+		t.NumOut() == 2 && t.Out(0) == ginHandlerFuncType && t.Out(1) == errorType
+}
+
+// asParameterizedMiddleware converts a bound parameterized middleware method, nil if it is not one.
+func asParameterizedMiddleware(method any) ParameterizedMiddleware {
+	if f, ok := method.(func(string) (gin.HandlerFunc, error)); ok {
+		return ParameterizedMiddlewareFunc(f)
+	}
+
+	return nil
+}
+
+// splitMiddlewareEntry splits an entry of a `middlewares` tag into the middleware name and its argument,
+// e.g. "ratelimit=100" into "ratelimit" and "100".
+func splitMiddlewareEntry(entry string) (name, arg string, hasArg bool) {
+	return strings.Cut(entry, "=")
+}
+
+// middlewareHandler returns the handler of mw for the argument it is referenced with. The handlers of
+// parameterized middlewares are built once per argument; other middlewares take no argument, so it is
+// skipped with a warning carrying the given args.
+func (c *core) middlewareHandler(mw *Middleware, arg string, hasArg bool, args ...any) (gin.HandlerFunc, error) {
+	if mw.factory == nil {
+		if hasArg {
+			c.warn("skipping middleware argument because the middleware is not parameterized",
+				append(args, "middleware", mw.middleware, "argument", arg)...,
+			)
+		}
+
+		return mw.handler, nil
+	}
+
+	key := mw.middleware + "=" + arg
+	if handler, ok := c.parameterizedHandlers[key]; ok {
+		return handler, nil
+	}
+
+	handler, err := mw.factory.Middleware(arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build middleware %s with argument %q: %w", mw.middleware, arg, err)
+	} else if handler == nil {
+		return nil, fmt.Errorf("failed to build middleware %s with argument %q: no handler", mw.middleware, arg)
+	}
+
+	if c.parameterizedHandlers == nil {
+		c.parameterizedHandlers = make(map[string]gin.HandlerFunc)
+	}
+	c.parameterizedHandlers[key] = handler
+
+	return handler, nil
+}
@@ -0,0 +1,127 @@
+package httpbara
+
+import (
+	"cmp"
+	"github.com/gin-gonic/gin"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ResponseEncoder writes obj with the given status code in a specific media type,
+// e.g. `func(ctx *gin.Context, code int, obj any) { ctx.YAML(code, obj) }`.
+type ResponseEncoder func(ctx *gin.Context, code int, obj any)
+
+// defaultResponseEncoders are always available; WithResponseEncoders can add or override media types.
+func defaultResponseEncoders() map[string]ResponseEncoder {
+	return map[string]ResponseEncoder{
+		"application/json": func(ctx *gin.Context, code int, obj any) {
+			ctx.JSON(code, obj)
+		},
+		"application/xml": func(ctx *gin.Context, code int, obj any) {
+			ctx.XML(code, obj)
+		},
+	}
+}
+
+// getResponseCallback negotiates the response encoding from the Accept header of the request.
+// Media ranges are ordered by their q-value (ties keep header order) and the first one with a registered
+// encoder wins. A `type/*` range matches the encoders of that type, JSON first, then in alphabetical order.
+// `*/*` and headers without a matching encoder fall back to JSON.
+// Headers carried by the response envelope (see headerCarrier) are set before the body is written.
+func (c *core) getResponseCallback(ctx *gin.Context) responseCallback {
+	encoder := c.responseEncoders["application/json"]
+
+	for _, mediaType := range parseAccept(ctx.GetHeader("Accept")) {
+		if e, ok := c.matchResponseEncoder(mediaType); ok {
+			encoder = e
+			break
+		}
+	}
+
+	return func(code int, obj any) {
+		if hc, ok := obj.(headerCarrier); ok {
+			for key, value := range hc.ResponseHeaders() {
+				ctx.Header(key, value)
+			}
+		}
+
+		encoder(ctx, code, obj)
+	}
+}
+
+// matchResponseEncoder returns the encoder registered for a media type, or for a `type/*` range the encoder
+// of that type preferred by getResponseCallback.
+func (c *core) matchResponseEncoder(mediaType string) (ResponseEncoder, bool) {
+	prefix, ok := strings.CutSuffix(mediaType, "/*")
+	if !ok || prefix == "*" {
+		encoder, ok := c.responseEncoders[mediaType]
+		return encoder, ok
+	}
+
+	if encoder, ok := c.responseEncoders["application/json"]; ok && prefix == "application" {
+		return encoder, true
+	}
+
+	matches := make([]string, 0)
+	for registered := range c.responseEncoders {
+		if strings.HasPrefix(registered, prefix+"/") {
+			matches = append(matches, registered)
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, false
+	}
+
+	return c.responseEncoders[slices.Min(matches)], true
+}
+
+// headerCarrier is implemented by response envelopes carrying headers to set on the response,
+// such as casual.HttpErrorResponse for errors created with casual.NewHTTPErrorWithHeaders.
+type headerCarrier interface {
+	ResponseHeaders() map[string]string
+}
+
+// parseAccept returns the media types of an Accept header ordered by descending q-value.
+// Media ranges with q=0 are dropped.
+func parseAccept(header string) []string {
+	type acceptRange struct {
+		mediaType string
+		q         float64
+	}
+
+	ranges := make([]acceptRange, 0)
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		if q <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+
+	// a stable sort keeps the header order for equal q-values
+	slices.SortStableFunc(ranges, func(a, b acceptRange) int {
+		return cmp.Compare(b.q, a.q)
+	})
+
+	result := make([]string, len(ranges))
+	for i, r := range ranges {
+		result[i] = r.mediaType
+	}
+
+	return result
+}
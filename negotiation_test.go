@@ -0,0 +1,79 @@
+package httpbara_test
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type negotiationResponse struct {
+	Name string `json:"name" xml:"name" yaml:"name"`
+}
+
+type negotiationHandlerDescriber struct {
+	Get httpbara.Route `route:"GET /item"`
+}
+
+type negotiationHandler struct {
+	negotiationHandlerDescriber
+}
+
+func (h *negotiationHandler) Get(ctx context.Context) (*negotiationResponse, error) {
+	return &negotiationResponse{Name: "bara"}, nil
+}
+
+func TestContentNegotiation(t *testing.T) {
+	encoders := httpbara.WithResponseEncoders(map[string]httpbara.ResponseEncoder{
+		"application/yaml": func(ctx *gin.Context, code int, obj any) { ctx.YAML(code, obj) },
+		"text/csv": func(ctx *gin.Context, code int, obj any) {
+			ctx.Data(code, "text/csv", []byte("name\nbara\n"))
+		},
+	})
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+	}{
+		{name: "no accept header", contentType: "application/json"},
+		{name: "json", accept: "application/json", contentType: "application/json"},
+		{name: "xml", accept: "application/xml", contentType: "application/xml"},
+		{name: "highest q-value", accept: "application/yaml;q=0.9, application/json;q=0.8", contentType: "application/yaml"},
+		{name: "header order on ties", accept: "application/xml, application/yaml", contentType: "application/xml"},
+		{name: "q=0 dropped", accept: "application/yaml;q=0, application/xml;q=0.1", contentType: "application/xml"},
+		{name: "unknown type", accept: "application/msgpack", contentType: "application/json"},
+		{name: "unknown type before known", accept: "application/msgpack, application/yaml;q=0.5", contentType: "application/yaml"},
+		{name: "any type", accept: "*/*", contentType: "application/json"},
+		{name: "application wildcard", accept: "application/*", contentType: "application/json"},
+		{name: "text wildcard", accept: "text/*", contentType: "text/csv"},
+		{name: "unknown wildcard", accept: "image/*", contentType: "application/json"},
+		{name: "malformed", accept: ";;;", contentType: "application/json"},
+	}
+
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &negotiationHandler{})}, encoders)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.accept != "" {
+				headers["Accept"] = tt.accept
+			}
+
+			rec := serve(h, http.MethodGet, "/item", nil, headers)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+				t.Fatalf("content type = %q, want %q", got, tt.contentType)
+			}
+
+			if !strings.Contains(rec.Body.String(), "bara") {
+				t.Fatalf("body = %q, want the encoded response", rec.Body.String())
+			}
+		})
+	}
+}
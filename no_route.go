@@ -0,0 +1,28 @@
+package httpbara
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+)
+
+// noRouteHandler renders casual.ErrNotFound for requests that match no route.
+func (c *core) noRouteHandler(ctx *gin.Context) {
+	rcb := c.getResponseCallback(ctx)
+	rcb(c.casualResponseErrorHandler(casual.ErrNotFound, languageParams(ctx)...))
+	ctx.Abort()
+}
+
+// applyNoRoute makes the Gin engine answer requests that match no route with the handlers configured
+// via WithNoRouteHandler instead of Gin's plain text 404.
+func (c *core) applyNoRoute() {
+	if !c.noRoute {
+		return
+	}
+
+	handlers := c.noRouteHandlers
+	if len(handlers) == 0 {
+		handlers = []gin.HandlerFunc{c.noRouteHandler}
+	}
+
+	c.gin.NoRoute(handlers...)
+}
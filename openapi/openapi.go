@@ -0,0 +1,277 @@
+// Package openapi generates an OpenAPI 3.0 document from httpbara handlers.
+//
+// Routes, methods, paths and groups come from the describer tags, request bodies and query parameters from the
+// request type of casual handlers and response schemas from the data type they return, wrapped in the casual envelope.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+type generateOpts struct {
+	title       string
+	version     string
+	description string
+}
+
+type GenerateOpt func(*generateOpts)
+
+func WithTitle(title string) GenerateOpt {
+	return func(opts *generateOpts) {
+		opts.title = title
+	}
+}
+
+func WithVersion(version string) GenerateOpt {
+	return func(opts *generateOpts) {
+		opts.version = version
+	}
+}
+
+func WithDescription(description string) GenerateOpt {
+	return func(opts *generateOpts) {
+		opts.description = description
+	}
+}
+
+// Generate builds an OpenAPI 3.0 document describing the routes of the given handlers and returns it as JSON.
+// Path parameters are inferred from `:name` and `*name` segments, bodies of POST, PUT and PATCH routes from the
+// JSON representation of the casual request type, query parameters of other routes from its `form` tags,
+// and success responses from the casual handler's data type wrapped in the casual.HttpResponse envelope.
+//
+// **Example:**
+// ```go
+//
+//	doc, err := openapi.Generate(handlers, openapi.WithTitle("Shop API"), openapi.WithVersion("3.0.0"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	_ = os.WriteFile("openapi.json", doc, 0o644)
+//
+// ```
+func Generate(handlers []*httpbara.Handler, opts ...GenerateOpt) ([]byte, error) {
+	o := generateOpts{
+		title:   "httpbara",
+		version: "1.0.0",
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	specs, err := httpbara.DescribeRoutes(handlers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe routes: %w", err)
+	}
+
+	g := newGenerator()
+	paths := make(map[string]map[string]*operation)
+
+	for _, spec := range specs {
+		path, params := convertPath(spec.Path, spec.Constraints)
+		if paths[path] == nil {
+			paths[path] = make(map[string]*operation)
+		}
+
+		methods := []string{strings.ToUpper(spec.Method)}
+		if methods[0] == "ANY" {
+			methods = []string{
+				http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+				http.MethodHead, http.MethodOptions, http.MethodDelete,
+			}
+		}
+
+		for _, method := range methods {
+			paths[path][strings.ToLower(method)] = g.operation(spec, method, params)
+		}
+	}
+
+	doc := document{
+		OpenAPI: "3.0.3",
+		Info: info{
+			Title:       o.title,
+			Version:     o.version,
+			Description: o.description,
+		},
+		Paths: paths,
+	}
+
+	if len(g.schemas) > 0 {
+		doc.Components = &components{Schemas: g.schemas}
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// convertPath turns a Gin path into an OpenAPI path template and returns its path parameters.
+func convertPath(path string, constraints map[string]string) (string, []*parameter) {
+	segments := strings.Split(path, "/")
+	params := make([]*parameter, 0)
+
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			continue
+		}
+
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+
+		paramSchema := &schema{Type: "string"}
+		switch constraints[name] {
+		case "int":
+			paramSchema = &schema{Type: "integer", Format: "int64"}
+		case "uuid":
+			paramSchema = &schema{Type: "string", Format: "uuid"}
+		}
+
+		params = append(params, &parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   paramSchema,
+		})
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+func (g *generator) operation(spec httpbara.RouteSpec, method string, pathParams []*parameter) *operation {
+	op := &operation{
+		OperationID: spec.Name,
+		Parameters:  append([]*parameter{}, pathParams...),
+		Responses:   make(map[string]*response),
+	}
+
+	if len(spec.Constraints) > 0 {
+		op.Responses["400"] = &response{Description: "Invalid path parameters", Content: g.errorContent()}
+	}
+
+	if spec.Group != "" {
+		op.Tags = []string{spec.Group}
+	}
+
+	if spec.Request != nil {
+		switch method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			op.RequestBody = &requestBody{
+				Required: true,
+				Content: map[string]*mediaType{
+					"application/json": {Schema: g.schemaFor(spec.Request)},
+				},
+			}
+		default:
+			op.Parameters = append(op.Parameters, g.queryParameters(spec.Request)...)
+		}
+
+		op.Responses["default"] = &response{Description: "Error", Content: g.errorContent()}
+	}
+
+	switch {
+	case spec.Response != nil:
+		op.Responses["200"] = &response{
+			Description: "OK",
+			Content: map[string]*mediaType{
+				"application/json": {Schema: g.envelope(spec.Response)},
+			},
+		}
+	default:
+		op.Responses["200"] = &response{Description: "OK"}
+	}
+
+	if len(op.Parameters) == 0 {
+		op.Parameters = nil
+	}
+
+	return op
+}
+
+// queryParameters lists the fields of a request struct bound from the query string through `form` tags.
+func (g *generator) queryParameters(t reflect.Type) []*parameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	params := make([]*parameter, 0)
+	if t.Kind() != reflect.Struct {
+		return params
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			params = append(params, g.queryParameters(field.Type)...)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		params = append(params, &parameter{
+			Name:     name,
+			In:       "query",
+			Required: isRequired(field),
+			Schema:   g.schemaFor(field.Type),
+		})
+	}
+
+	return params
+}
+
+// envelope wraps the schema of the data type in the casual.HttpResponse envelope.
+func (g *generator) envelope(t reflect.Type) *schema {
+	return &schema{
+		Type: "object",
+		Properties: map[string]*schema{
+			"status": {Type: "integer"},
+			"data":   g.schemaFor(t),
+			"meta":   {Type: "object", AdditionalProperties: &schema{}},
+		},
+		Required: []string{"status"},
+	}
+}
+
+func (g *generator) errorContent() map[string]*mediaType {
+	if _, ok := g.schemas["HttpErrorResponse"]; !ok {
+		g.schemas["HttpErrorResponse"] = &schema{
+			Type: "object",
+			Properties: map[string]*schema{
+				"status": {Type: "integer"},
+				"error": {
+					Type: "object",
+					Properties: map[string]*schema{
+						"code":    {},
+						"message": {Type: "string"},
+						"details": {
+							Type: "array",
+							Items: &schema{
+								Type: "object",
+								Properties: map[string]*schema{
+									"field": {Type: "string"},
+									"issue": {Type: "string"},
+								},
+							},
+						},
+					},
+					Required: []string{"message"},
+				},
+				"meta": {Type: "object", AdditionalProperties: &schema{}},
+			},
+			Required: []string{"status", "error"},
+		}
+	}
+
+	return map[string]*mediaType{
+		"application/json": {Schema: &schema{Ref: "#/components/schemas/HttpErrorResponse"}},
+	}
+}
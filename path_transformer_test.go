@@ -0,0 +1,102 @@
+package httpbara_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// kebabCase rewrites the static segments of a path from camelCase to kebab-case, keeping path parameters intact.
+func kebabCase(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			continue
+		}
+
+		var b strings.Builder
+		for j, r := range segment {
+			if unicode.IsUpper(r) {
+				if j > 0 {
+					b.WriteByte('-')
+				}
+				r = unicode.ToLower(r)
+			}
+			b.WriteRune(r)
+		}
+		segments[i] = b.String()
+	}
+
+	return strings.Join(segments, "/")
+}
+
+type camelCaseHandlerDescriber struct {
+	AdminArea httpbara.Group `group:"/adminArea"`
+
+	CamelCase httpbara.Route `route:"GET /camelCase"`
+	UserPosts httpbara.Route `route:"GET /userAccounts/:userId/blogPosts" group:"adminArea"`
+}
+
+type camelCaseHandler struct {
+	camelCaseHandlerDescriber
+}
+
+func (h *camelCaseHandler) CamelCase(ctx *gin.Context) {
+	ctx.String(http.StatusOK, "camel")
+}
+
+func (h *camelCaseHandler) UserPosts(ctx *gin.Context) {
+	ctx.String(http.StatusOK, "posts of "+ctx.Param("userId"))
+}
+
+func TestWithPathTransformer(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		target   string
+		wantBody string
+		oldPath  string
+	}{
+		{name: "route", path: "/camel-case", target: "/camel-case", wantBody: "camel", oldPath: "/camelCase"},
+		{
+			name:     "group and path parameter",
+			path:     "/admin-area/user-accounts/:userId/blog-posts",
+			target:   "/admin-area/user-accounts/42/blog-posts",
+			wantBody: "posts of 42",
+			oldPath:  "/adminArea/userAccounts/42/blogPosts",
+		},
+	}
+
+	engine, err := httpbara.New([]*httpbara.Handler{mustHandler(t, &camelCaseHandler{})},
+		httpbara.WithLogger(discardLogger{}),
+		httpbara.WithPathTransformer(kebabCase),
+	)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	paths := make(map[string]struct{})
+	for _, route := range engine.Routes() {
+		paths[route.Path] = struct{}{}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := paths[tt.path]; !ok {
+				t.Fatalf("routes = %v, want %q", paths, tt.path)
+			}
+
+			rec := serve(engine.AsHTTPHandler(), http.MethodGet, tt.target, nil, nil)
+			if rec.Code != http.StatusOK || rec.Body.String() != tt.wantBody {
+				t.Fatalf("GET %s = %d %q, want %d %q", tt.target, rec.Code, rec.Body.String(), http.StatusOK, tt.wantBody)
+			}
+
+			if rec := serve(engine.AsHTTPHandler(), http.MethodGet, tt.oldPath, nil, nil); rec.Code != http.StatusNotFound {
+				t.Fatalf("GET %s = %d, want %d", tt.oldPath, rec.Code, http.StatusNotFound)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package httpbarafx
 
 import (
+	"context"
 	"fmt"
 	"github.com/gopybara/httpbara"
 	"go.uber.org/fx"
@@ -19,6 +20,10 @@ func NewHttpbaraServer(in NewExampleServerIn) (httpbara.Engine, error) {
 	)
 }
 
+// ProvideHttpbaraModule provides the engine built from the handlers of the "handlers" group and the options
+// of the "httpbaraOpts" group, and ties it to the fx lifecycle: the engine starts listening when the application
+// starts, on the port of the provided HttpbaraRunParams, and is shut down gracefully when the application stops,
+// within the stop timeout of the application. Start and shutdown errors are reported by fx.
 func ProvideHttpbaraModule() fx.Option {
 	return fx.Options(
 		fx.Provide(
@@ -35,14 +40,31 @@ type InvokeServerIn struct {
 	Params *HttpbaraRunParams `optional:"true"`
 }
 
+// invokeServer starts the engine when the fx application starts, so a port collision fails the start,
+// and shuts it down gracefully when the application stops.
 func invokeServer() fx.Option {
 	return fx.Invoke(
-		func(in InvokeServerIn) {
+		func(lc fx.Lifecycle, in InvokeServerIn) {
 			if in.Params == nil {
 				in.Params = &HttpbaraRunParams{Port: 1489}
 			}
 
-			go in.Engine.Run(fmt.Sprintf(":%d", in.Params.Port))
+			lc.Append(fx.Hook{
+				OnStart: func(context.Context) error {
+					if err := in.Engine.Start(fmt.Sprintf(":%d", in.Params.Port)); err != nil {
+						return fmt.Errorf("failed to start httpbara engine: %w", err)
+					}
+
+					return nil
+				},
+				OnStop: func(ctx context.Context) error {
+					if err := in.Engine.Stop(ctx); err != nil {
+						return fmt.Errorf("failed to stop httpbara engine: %w", err)
+					}
+
+					return nil
+				},
+			})
 		},
 	)
 }
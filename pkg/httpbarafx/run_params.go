@@ -1,5 +1,6 @@
 package httpbarafx
 
+// HttpbaraRunParams configures the engine started by ProvideHttpbaraModule. Without it the engine listens on port 1489.
 type HttpbaraRunParams struct {
 	Port int64
 }
@@ -0,0 +1,65 @@
+package httpbarahttp3
+
+import (
+	"context"
+	"github.com/gopybara/httpbara"
+	"github.com/quic-go/quic-go/http3"
+	"net/http"
+)
+
+// server serves the engine routes over HTTP/3 (QUIC) and implements httpbara.Server.
+type server struct {
+	srv      *http3.Server
+	certFile string
+	keyFile  string
+}
+
+// WithHTTP3 makes the engine serve its routes over HTTP/3 (QUIC) on the UDP address addr next to the
+// regular listeners. HTTP/3 always requires TLS, so certFile and keyFile must point to the same PEM encoded
+// certificate and key used by the HTTPS listener. Responses sent over HTTP/1.1 and HTTP/2 carry an Alt-Svc
+// header advertising the HTTP/3 endpoint, and the QUIC server is shut down gracefully together with the engine.
+//
+// **Example:**
+// ```go
+//
+//	engine, _ := httpbara.New(handlers, httpbarahttp3.WithHTTP3(":8443", "server.crt", "server.key"))
+//	err := engine.RunMulti([]httpbara.ListenConfig{
+//	    {Addr: ":8443", CertFile: "server.crt", KeyFile: "server.key"},
+//	})
+//
+// ```
+func WithHTTP3(addr, certFile, keyFile string) httpbara.ParamsCb {
+	s := &server{
+		srv:      &http3.Server{Addr: addr},
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	return httpbara.WithOptions(
+		httpbara.WithServers(s),
+		httpbara.WithHandlerWrapper(s.advertise),
+	)
+}
+
+func (s *server) Serve(handler http.Handler) error {
+	s.srv.Handler = handler
+
+	return s.srv.ListenAndServeTLS(s.certFile, s.keyFile)
+}
+
+func (s *server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+// advertise sets the Alt-Svc header on responses that were not served over HTTP/3 so that
+// clients can upgrade to QUIC on subsequent requests.
+func (s *server) advertise(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor < 3 {
+			// The header is unavailable until the QUIC listener is up, the request is served regardless.
+			_ = s.srv.SetQUICHeaders(w.Header())
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,187 @@
+package httpbarametrics
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type metricsOpts struct {
+	registerer prometheus.Registerer
+	gatherer   prometheus.Gatherer
+	namespace  string
+	buckets    []float64
+}
+
+type MetricsOpt func(*metricsOpts)
+
+// WithRegistry registers the metrics on the given registry, and makes the metrics handler expose it,
+// instead of the default Prometheus registry.
+func WithRegistry(registry *prometheus.Registry) MetricsOpt {
+	return func(opts *metricsOpts) {
+		opts.registerer = registry
+		opts.gatherer = registry
+	}
+}
+
+// WithNamespace prefixes the metric names with the given namespace, e.g. `shop_http_requests_total`.
+func WithNamespace(namespace string) MetricsOpt {
+	return func(opts *metricsOpts) {
+		opts.namespace = namespace
+	}
+}
+
+// WithBuckets sets the buckets of the request duration histogram in seconds, prometheus.DefBuckets by default.
+func WithBuckets(buckets ...float64) MetricsOpt {
+	return func(opts *metricsOpts) {
+		opts.buckets = buckets
+	}
+}
+
+func newMetricsOpts(opts []MetricsOpt) metricsOpts {
+	mo := metricsOpts{
+		registerer: prometheus.DefaultRegisterer,
+		gatherer:   prometheus.DefaultGatherer,
+		buckets:    prometheus.DefBuckets,
+	}
+
+	for _, opt := range opts {
+		opt(&mo)
+	}
+
+	return mo
+}
+
+type metricsMiddlewareDescriber struct {
+	Middleware httpbara.Middleware `middleware:"metrics"`
+}
+
+type metricsMiddleware struct {
+	metricsMiddlewareDescriber
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// NewMetricsMiddleware creates a middleware named "metrics" that records the `http_requests_total` counter,
+// the `http_request_duration_seconds` histogram, both labeled by method, route and status, and the
+// `http_requests_in_flight` gauge labeled by method and route. The route label is the route template
+// (e.g. "/products/:id"), never the concrete path, and is empty when no route matched.
+//
+// The metrics are registered on the default Prometheus registry unless WithRegistry is given. Creating the
+// middleware again for the same registry reuses the registered metrics. The middleware does not depend on
+// the tracing middleware of httpbaratelemetry, so both can be applied to the same routes.
+//
+// **Example:**
+// ```go
+//
+//	metrics, _ := httpbarametrics.NewMetricsMiddleware()
+//	metricsHandler, _ := httpbarametrics.NewMetricsHandler()
+//
+//	engine, _ := httpbara.New(append(handlers, metricsHandler), httpbara.WithRootMiddlewares(metrics))
+//
+// ```
+func NewMetricsMiddleware(opts ...MetricsOpt) (*httpbara.Handler, error) {
+	mo := newMetricsOpts(opts)
+
+	requests, err := register(mo.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: mo.namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of handled HTTP requests.",
+	}, []string{"method", "route", "status"}))
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := register(mo.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: mo.namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of handled HTTP requests in seconds.",
+		Buckets:   mo.buckets,
+	}, []string{"method", "route", "status"}))
+	if err != nil {
+		return nil, err
+	}
+
+	inFlight, err := register(mo.registerer, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: mo.namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Number of HTTP requests being handled.",
+	}, []string{"method", "route"}))
+	if err != nil {
+		return nil, err
+	}
+
+	mm := metricsMiddleware{
+		requests: requests,
+		duration: duration,
+		inFlight: inFlight,
+	}
+
+	return httpbara.AsHandler(&mm)
+}
+
+func (mm *metricsMiddleware) Middleware(ctx *gin.Context) {
+	ts := time.Now()
+	method := ctx.Request.Method
+	route := ctx.FullPath()
+
+	inFlight := mm.inFlight.WithLabelValues(method, route)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	ctx.Next()
+
+	status := strconv.Itoa(ctx.Writer.Status())
+	mm.requests.WithLabelValues(method, route, status).Inc()
+	mm.duration.WithLabelValues(method, route, status).Observe(time.Since(ts).Seconds())
+}
+
+// register registers collector, or returns the collector already registered with the same description.
+func register[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	if err := registerer.Register(collector); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+
+		return collector, fmt.Errorf("failed to register metric: %w", err)
+	}
+
+	return collector, nil
+}
+
+type metricsHandlerDescriber struct {
+	Metrics httpbara.Route `route:"GET /metrics"`
+}
+
+type metricsHandler struct {
+	metricsHandlerDescriber
+
+	handler http.Handler
+}
+
+// NewMetricsHandler creates a handler exposing the metrics of the default Prometheus registry, or of the
+// registry given with WithRegistry, on `GET /metrics`. Register it with the engine like any other handler.
+func NewMetricsHandler(opts ...MetricsOpt) (*httpbara.Handler, error) {
+	mo := newMetricsOpts(opts)
+
+	mh := metricsHandler{
+		handler: promhttp.HandlerFor(mo.gatherer, promhttp.HandlerOpts{}),
+	}
+
+	return httpbara.AsHandler(&mh)
+}
+
+func (mh *metricsHandler) Metrics(ctx *gin.Context) {
+	mh.handler.ServeHTTP(ctx.Writer, ctx.Request)
+}
@@ -0,0 +1,65 @@
+package httpbarametrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sizeBuckets are the buckets of the size histograms in bytes, from 100 B to 1 GB.
+var sizeBuckets = prometheus.ExponentialBuckets(100, 10, 8)
+
+type sizeRecorder struct {
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+// NewSizeRecorder creates an httpbara.SizeRecorder that records request and response body sizes as the
+// `http_request_size_bytes` and `http_response_size_bytes` histograms, labeled by method and route.
+// Pass it to httpbara.NewSizeMetricsMiddleware. WithRegistry and WithNamespace apply as for the metrics
+// middleware, while the buckets are fixed from 100 B to 1 GB.
+//
+// **Example:**
+// ```go
+//
+//	recorder, _ := httpbarametrics.NewSizeRecorder()
+//	sizes, _ := httpbara.NewSizeMetricsMiddleware(recorder)
+//
+//	engine, _ := httpbara.New(handlers, httpbara.WithRootMiddlewares(sizes))
+//
+// ```
+func NewSizeRecorder(opts ...MetricsOpt) (httpbara.SizeRecorder, error) {
+	mo := newMetricsOpts(opts)
+
+	requestSize, err := register(mo.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: mo.namespace,
+		Name:      "http_request_size_bytes",
+		Help:      "Size of HTTP request bodies in bytes.",
+		Buckets:   sizeBuckets,
+	}, []string{"method", "route"}))
+	if err != nil {
+		return nil, err
+	}
+
+	responseSize, err := register(mo.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: mo.namespace,
+		Name:      "http_response_size_bytes",
+		Help:      "Size of HTTP response bodies in bytes.",
+		Buckets:   sizeBuckets,
+	}, []string{"method", "route"}))
+	if err != nil {
+		return nil, err
+	}
+
+	return &sizeRecorder{
+		requestSize:  requestSize,
+		responseSize: responseSize,
+	}, nil
+}
+
+func (sr *sizeRecorder) RecordSizes(ctx *gin.Context, route string, requestSize int64, responseSize int64) {
+	method := ctx.Request.Method
+
+	sr.requestSize.WithLabelValues(method, route).Observe(float64(requestSize))
+	sr.responseSize.WithLabelValues(method, route).Observe(float64(responseSize))
+}
@@ -0,0 +1,50 @@
+package httpbaratelemetry
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type sizeRecorder struct {
+	requestSize  metric.Int64Histogram
+	responseSize metric.Int64Histogram
+}
+
+// NewSizeRecorder creates an httpbara.SizeRecorder that records request and response body sizes as
+// the `http.server.request.body.size` and `http.server.response.body.size` histograms of the given meter,
+// labeled by route and method. Pass it to httpbara.NewSizeMetricsMiddleware.
+func NewSizeRecorder(meter metric.Meter) (httpbara.SizeRecorder, error) {
+	requestSize, err := meter.Int64Histogram("http.server.request.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server request bodies."),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request size histogram: %w", err)
+	}
+
+	responseSize, err := meter.Int64Histogram("http.server.response.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server response bodies."),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create response size histogram: %w", err)
+	}
+
+	return &sizeRecorder{
+		requestSize:  requestSize,
+		responseSize: responseSize,
+	}, nil
+}
+
+func (sr *sizeRecorder) RecordSizes(ctx *gin.Context, route string, requestSize int64, responseSize int64) {
+	attrs := metric.WithAttributes(
+		attribute.String("http.route", route),
+		attribute.String("http.request.method", ctx.Request.Method),
+	)
+
+	sr.requestSize.Record(ctx.Request.Context(), requestSize, attrs)
+	sr.responseSize.Record(ctx.Request.Context(), responseSize, attrs)
+}
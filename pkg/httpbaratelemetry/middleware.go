@@ -1,11 +1,15 @@
 package httpbaratelemetry
 
 import (
-	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gopybara/httpbara"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"time"
 )
 
 type otelMiddlewareDescriber struct {
@@ -15,36 +19,85 @@ type otelMiddlewareDescriber struct {
 type otelMiddleware struct {
 	otelMiddlewareDescriber
 
-	tp TelemetryProvider
+	tp       TelemetryProvider
+	duration metric.Float64Histogram
 }
 
+// NewOtelMiddleware creates a middleware named "otelInjector" tracing every request, see InjectTrace. The duration
+// of requests is recorded as the `http.server.request.duration` histogram of the meter named "httpbara", labeled by
+// method, route and status code; it is a no-op unless the provider was created with WithMeterProvider.
 func NewOtelMiddleware(tp TelemetryProvider) (*httpbara.Handler, error) {
+	duration, err := tp.Meter("httpbara").Float64Histogram("http.server.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP server requests."),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request duration histogram: %w", err)
+	}
+
 	omi := otelMiddleware{
-		tp: tp,
+		tp:       tp,
+		duration: duration,
 	}
 
 	return httpbara.AsHandler(&omi)
 }
 
+// InjectTrace starts a server span for the request, as a child of the trace of an incoming `traceparent` header
+// if any, and records the `http.method`, `http.route`, `http.target` and `http.status_code` attributes on it.
+// The span context replaces the incoming one in the request headers and context, so handlers forwarding them
+// continue the trace from this span. Incoming W3C baggage is made available through the request context,
+// see WithPropagators. Responses with a 5xx status set the span status to Error, as do panics, which are passed
+// on to the recovery middleware of the engine. The duration of the request is recorded once it is handled,
+// see NewOtelMiddleware.
 func (omi *otelMiddleware) InjectTrace(ctx *gin.Context) {
+	ts := time.Now()
 	spanName := ctx.Request.Method + " " + ctx.FullPath()
-	var traceCtx context.Context
-	var span trace.Span
 
-	if ctx.GetHeader("traceparent") != "" {
-		ctx.Request = ctx.Request.WithContext(omi.tp.propagator().Extract(ctx, propagation.HeaderCarrier(ctx.Request.Header)))
+	// The extracted remote span context, if any, parents the server span; baggage is extracted even without it
+	remoteCtx := omi.tp.propagator().Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
 
-		span = trace.SpanFromContext(ctx.Request.Context())
-		traceCtx = trace.ContextWithSpan(ctx.Request.Context(), span)
-	} else {
-		traceCtx, span = omi.tp.NewSpan(ctx.Request.Context(), spanName)
-		ctx.Request.Header.Set("Traceparent", omi.tp.createTraceparent(traceCtx))
-		omi.tp.propagator().Inject(traceCtx, propagation.HeaderCarrier(ctx.Request.Header))
+	traceCtx, span := omi.tp.NewSpan(remoteCtx, spanName)
+	ctx.Request.Header.Set("Traceparent", omi.tp.createTraceparent(traceCtx))
+	omi.tp.propagator().Inject(traceCtx, propagation.HeaderCarrier(ctx.Request.Header))
 
-		ctx.Request = ctx.Request.WithContext(traceCtx)
-	}
+	ctx.Request = ctx.Request.WithContext(traceCtx)
 
 	defer span.End()
 
+	span.SetAttributes(
+		attribute.String("http.method", ctx.Request.Method),
+		attribute.String("http.route", ctx.FullPath()),
+		attribute.String("http.target", ctx.Request.URL.RequestURI()),
+	)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			span.SetAttributes(attribute.Int("http.status_code", http.StatusInternalServerError))
+			span.SetStatus(codes.Error, fmt.Sprint(recovered))
+			omi.recordDuration(ctx, ts, http.StatusInternalServerError)
+
+			panic(recovered)
+		}
+	}()
+
 	ctx.Next()
+
+	status := ctx.Writer.Status()
+	span.SetAttributes(attribute.Int("http.status_code", status))
+
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+
+	omi.recordDuration(ctx, ts, status)
+}
+
+// recordDuration records the time elapsed since ts in the request duration histogram.
+func (omi *otelMiddleware) recordDuration(ctx *gin.Context, ts time.Time, status int) {
+	omi.duration.Record(ctx.Request.Context(), time.Since(ts).Seconds(), metric.WithAttributes(
+		attribute.String("http.request.method", ctx.Request.Method),
+		attribute.String("http.route", ctx.FullPath()),
+		attribute.Int("http.response.status_code", status),
+	))
 }
@@ -0,0 +1,173 @@
+package httpbaratelemetry_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"github.com/gopybara/httpbara/pkg/httpbaratelemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	remoteTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	remoteSpanID  = "00f067aa0ba902b7"
+)
+
+// discardLogger is a httpbara.Logger dropping every message, keeping the test output readable.
+type discardLogger struct{}
+
+func (discardLogger) Info(string, ...any)  {}
+func (discardLogger) Debug(string, ...any) {}
+func (discardLogger) Error(string, ...any) {}
+func (discardLogger) Panic(string, ...any) {}
+func (discardLogger) Warn(string, ...any)  {}
+
+type tracedHandlerDescriber struct {
+	Get  httpbara.Route `route:"GET /items/:id"`
+	Fail httpbara.Route `route:"GET /fail"`
+}
+
+// tracedHandler reports the trace ID and traceparent seen by the handler.
+type tracedHandler struct {
+	tracedHandlerDescriber
+}
+
+func (h *tracedHandler) Get(ctx *gin.Context) {
+	ctx.Header("X-Trace-Id", trace.SpanContextFromContext(ctx.Request.Context()).TraceID().String())
+	ctx.Header("X-Traceparent", ctx.GetHeader("traceparent"))
+	ctx.Status(http.StatusOK)
+}
+
+func (h *tracedHandler) Fail(ctx *gin.Context) {
+	ctx.Status(http.StatusBadGateway)
+}
+
+func TestInjectTrace(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		traceparent string
+		status      int
+		spanStatus  codes.Code
+	}{
+		{name: "new trace", target: "/items/42?expand=1", status: http.StatusOK, spanStatus: codes.Unset},
+		{
+			name:        "incoming traceparent",
+			target:      "/items/42?expand=1",
+			traceparent: "00-" + remoteTraceID + "-" + remoteSpanID + "-01",
+			status:      http.StatusOK,
+			spanStatus:  codes.Unset,
+		},
+		{name: "server error", target: "/fail", status: http.StatusBadGateway, spanStatus: codes.Error},
+		{
+			name:        "server error with incoming traceparent",
+			target:      "/fail",
+			traceparent: "00-" + remoteTraceID + "-" + remoteSpanID + "-01",
+			status:      http.StatusBadGateway,
+			spanStatus:  codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp, err := httpbaratelemetry.NewProvider(
+				httpbaratelemetry.WithTraceProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+				httpbaratelemetry.WithTelemetryLogger(discardLogger{}),
+			)
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			otel, err := httpbaratelemetry.NewOtelMiddleware(tp)
+			if err != nil {
+				t.Fatalf("failed to create middleware: %v", err)
+			}
+
+			handler, err := httpbara.AsHandler(&tracedHandler{})
+			if err != nil {
+				t.Fatalf("failed to create handler: %v", err)
+			}
+
+			engine, err := httpbara.New([]*httpbara.Handler{handler},
+				httpbara.WithLogger(discardLogger{}),
+				httpbara.WithRootMiddlewares(otel),
+			)
+			if err != nil {
+				t.Fatalf("failed to create engine: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+
+			rec := httptest.NewRecorder()
+			engine.AsHTTPHandler().ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("recorded %d spans, want 1", len(spans))
+			}
+
+			span := spans[0]
+			if tt.traceparent != "" {
+				if span.SpanContext().TraceID().String() != remoteTraceID || span.Parent().SpanID().String() != remoteSpanID {
+					t.Fatalf("span %s with parent %s, want a child of the incoming span %s-%s",
+						span.SpanContext().TraceID(), span.Parent().SpanID(), remoteTraceID, remoteSpanID)
+				}
+
+				if !span.Parent().IsRemote() {
+					t.Fatal("span parent is not the remote span context")
+				}
+			} else if span.Parent().IsValid() {
+				t.Fatalf("span parent = %s, want a root span", span.Parent().SpanID())
+			}
+
+			attrs := make(map[attribute.Key]attribute.Value)
+			for _, attr := range span.Attributes() {
+				attrs[attr.Key] = attr.Value
+			}
+
+			if got := attrs["http.method"].AsString(); got != http.MethodGet {
+				t.Fatalf("http.method = %q, want %q", got, http.MethodGet)
+			}
+
+			if got := attrs["http.target"].AsString(); got != tt.target {
+				t.Fatalf("http.target = %q, want %q", got, tt.target)
+			}
+
+			if got := attrs["http.route"].AsString(); got == "" {
+				t.Fatal("http.route is not set")
+			}
+
+			if got := attrs["http.status_code"].AsInt64(); got != int64(tt.status) {
+				t.Fatalf("http.status_code = %d, want %d", got, tt.status)
+			}
+
+			if span.Status().Code != tt.spanStatus {
+				t.Fatalf("span status = %v, want %v", span.Status().Code, tt.spanStatus)
+			}
+
+			if tt.status == http.StatusOK {
+				if got := rec.Header().Get("X-Trace-Id"); got != span.SpanContext().TraceID().String() {
+					t.Fatalf("handler trace ID = %q, want %q", got, span.SpanContext().TraceID())
+				}
+
+				want := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+				if got := rec.Header().Get("X-Traceparent"); got != want {
+					t.Fatalf("forwarded traceparent = %q, want the server span %q", got, want)
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"github.com/gopybara/httpbara"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
@@ -21,6 +24,10 @@ type TelemetryProvider interface {
 	NewSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span)
 	CurrentSpan(ctx context.Context) trace.Span
 
+	// Meter returns the meter with the given instrumentation name from the meter provider set via
+	// WithMeterProvider, or a no-op meter when none was set.
+	Meter(name string) metric.Meter
+
 	createTraceparent(ctx context.Context) string
 	propagator() propagation.TextMapPropagator
 	Provider() *sdktrace.TracerProvider
@@ -32,12 +39,24 @@ type loggerWithContext struct {
 	ctx           context.Context
 	l             httpbara.Logger
 	telemetryKeys *TelemetryKeys
+	baggageFields []string
 }
 
 func (lwc *loggerWithContext) addSpanToFields(fields *[]any) {
 	if span := trace.SpanFromContext(lwc.ctx); span != nil {
 		*fields = append(*fields, lwc.telemetryKeys.TraceID, span.SpanContext().TraceID().String(), lwc.telemetryKeys.SpanID, span.SpanContext().SpanID().String())
 	}
+
+	if len(lwc.baggageFields) == 0 {
+		return
+	}
+
+	bag := baggage.FromContext(lwc.ctx)
+	for _, key := range lwc.baggageFields {
+		if member := bag.Member(key); member.Key() != "" {
+			*fields = append(*fields, key, member.Value())
+		}
+	}
 }
 
 func (lwc *loggerWithContext) Info(msg string, fields ...any) {
@@ -64,6 +83,7 @@ func (lwc *loggerWithContext) Warn(msg string, fields ...any) {
 	lwc.l.Warn(msg, fields...)
 }
 
+// Panic delegates to the wrapped logger, so whether it panics after logging depends on that logger.
 func (lwc *loggerWithContext) Panic(msg string, fields ...any) {
 	lwc.addSpanToFields(&fields)
 
@@ -75,11 +95,13 @@ type telemetryOpts struct {
 
 	tracerName    string `default:"httpbara"`
 	traceProvider *sdktrace.TracerProvider
+	meterProvider metric.MeterProvider
 	// Can be empty
-	// If empty propagation.TraceContext will be used by default
+	// If empty propagation.TraceContext and propagation.Baggage will be used by default
 	propagator propagation.TextMapPropagator
 
 	telemetryKeys *TelemetryKeys
+	baggageFields []string
 }
 
 type TelemetryKeys struct {
@@ -113,6 +135,32 @@ func WithTraceProvider(tp *sdktrace.TracerProvider) TelemetryOpt {
 	}
 }
 
+// WithBaggageLogFields makes the loggers returned by LogWithContext add the given baggage members of the context
+// as fields named after them, e.g. "tenant_id", next to the trace and span IDs. Members missing from the baggage
+// are omitted.
+func WithBaggageLogFields(keys ...string) TelemetryOpt {
+	return func(opts *telemetryOpts) {
+		opts.baggageFields = append(opts.baggageFields, keys...)
+	}
+}
+
+// WithPropagators sets the propagators extracting the trace context and baggage of incoming requests and
+// injecting them into outgoing ones, composed in the given order. By default the W3C trace context and
+// baggage propagators are used.
+func WithPropagators(propagators ...propagation.TextMapPropagator) TelemetryOpt {
+	return func(opts *telemetryOpts) {
+		opts.propagator = propagation.NewCompositeTextMapPropagator(propagators...)
+	}
+}
+
+// WithMeterProvider sets the meter provider of the telemetry provider, used by Meter. When set, the otel
+// middleware also records the duration of requests as a histogram.
+func WithMeterProvider(mp metric.MeterProvider) TelemetryOpt {
+	return func(opts *telemetryOpts) {
+		opts.meterProvider = mp
+	}
+}
+
 type providerImpl struct {
 	opts telemetryOpts
 }
@@ -128,6 +176,7 @@ func (pi *providerImpl) LogWithContext(ctx context.Context) httpbara.Logger {
 		tp:            pi.opts.traceProvider,
 		l:             pi.opts.log,
 		telemetryKeys: pi.opts.telemetryKeys,
+		baggageFields: pi.opts.baggageFields,
 	}
 }
 
@@ -143,6 +192,10 @@ func (pi *providerImpl) CurrentSpan(ctx context.Context) trace.Span {
 	return trace.SpanFromContext(ctx)
 }
 
+func (pi *providerImpl) Meter(name string) metric.Meter {
+	return pi.opts.meterProvider.Meter(name)
+}
+
 func (pi *providerImpl) createTraceparent(ctx context.Context) string {
 	sc := trace.SpanFromContext(ctx).SpanContext()
 
@@ -163,7 +216,7 @@ func NewProvider(opts ...TelemetryOpt) (TelemetryProvider, error) {
 			TraceID: "trace_id",
 			SpanID:  "span_id",
 		},
-		propagator: propagation.TraceContext{},
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
 		tracerName: "httpbara",
 	}
 
@@ -179,6 +232,10 @@ func NewProvider(opts ...TelemetryOpt) (TelemetryProvider, error) {
 		return nil, ErrTracerProviderNotSet
 	}
 
+	if to.meterProvider == nil {
+		to.meterProvider = noop.NewMeterProvider()
+	}
+
 	return &providerImpl{
 		opts: to,
 	}, nil
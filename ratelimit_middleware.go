@@ -0,0 +1,172 @@
+package httpbara
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+	"golang.org/x/time/rate"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidRateLimit is returned by NewRateLimitMiddleware when no store is set and the rate or burst is not positive.
+	ErrInvalidRateLimit = errors.New("rate limit rate and burst must be positive")
+)
+
+const defaultRateLimitIdleTimeout = 10 * time.Minute
+
+// RateLimitStore keeps the token buckets of the rate limit middleware.
+type RateLimitStore interface {
+	// Take takes a token from the bucket of key. It reports whether the request is allowed and,
+	// when it is not, how long until a token becomes available.
+	Take(key string) (bool, time.Duration)
+}
+
+// RateLimitOptions configures the middleware created by NewRateLimitMiddleware.
+//
+// Fields:
+// - `Rate`: The number of requests per second refilled into every bucket.
+// - `Burst`: The bucket size, i.e. the number of requests allowed at once.
+// - `KeyFunc`: Returns the bucket key of a request. Defaults to the client IP.
+// - `IdleTimeout`: How long an unused bucket is kept by the in-memory store. Defaults to 10 minutes.
+// - `Store`: A custom store, e.g. shared between instances. When set, `Rate`, `Burst` and `IdleTimeout` are ignored.
+type RateLimitOptions struct {
+	Rate        float64
+	Burst       int
+	KeyFunc     func(ctx *gin.Context) string
+	IdleTimeout time.Duration
+	Store       RateLimitStore
+}
+
+type rateLimitMiddlewareDescriber struct {
+	Middleware Middleware `middleware:"ratelimit"`
+}
+
+type rateLimitMiddleware struct {
+	rateLimitMiddlewareDescriber
+
+	keyFunc func(ctx *gin.Context) string
+	store   RateLimitStore
+}
+
+// NewRateLimitMiddleware creates a middleware named "ratelimit" that limits requests with a token bucket per key,
+// the client IP by default. Requests exceeding the limit are answered with casual.ErrTooManyRequests (429)
+// and a `Retry-After` header.
+//
+// **Example:**
+// ```go
+//
+//	ratelimit, _ := httpbara.NewRateLimitMiddleware(httpbara.RateLimitOptions{
+//	    Rate:  5,
+//	    Burst: 10,
+//	    KeyFunc: func(ctx *gin.Context) string {
+//	        return ctx.GetHeader("X-Api-Key")
+//	    },
+//	})
+//
+// ```
+func NewRateLimitMiddleware(opts RateLimitOptions) (*Handler, error) {
+	rlm := rateLimitMiddleware{
+		keyFunc: opts.KeyFunc,
+		store:   opts.Store,
+	}
+
+	if rlm.keyFunc == nil {
+		rlm.keyFunc = func(ctx *gin.Context) string {
+			return ctx.ClientIP()
+		}
+	}
+
+	if rlm.store == nil {
+		if opts.Rate <= 0 || opts.Burst <= 0 {
+			return nil, ErrInvalidRateLimit
+		}
+
+		rlm.store = NewMemoryRateLimitStore(opts.Rate, opts.Burst, opts.IdleTimeout)
+	}
+
+	return AsHandler(&rlm)
+}
+
+func (rlm *rateLimitMiddleware) Middleware(ctx *gin.Context) {
+	allowed, retryAfter := rlm.store.Take(rlm.keyFunc(ctx))
+	if !allowed {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		casual.Fail(ctx, casual.ErrTooManyRequests)
+		return
+	}
+
+	ctx.Next()
+}
+
+// memoryRateLimitStore keeps a token bucket per key in memory. Buckets unused for longer than idleTimeout
+// are removed by a sweep that runs at most once per idleTimeout while requests are taken.
+type memoryRateLimitStore struct {
+	limit       rate.Limit
+	burst       int
+	idleTimeout time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*rateLimitBucket
+	lastSweep time.Time
+}
+
+type rateLimitBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewMemoryRateLimitStore creates an in-memory RateLimitStore refilling ratePerSecond tokens per second into
+// buckets of size burst. Buckets unused for idleTimeout (10 minutes when zero) are cleaned up.
+func NewMemoryRateLimitStore(ratePerSecond float64, burst int, idleTimeout time.Duration) RateLimitStore {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultRateLimitIdleTimeout
+	}
+
+	return &memoryRateLimitStore{
+		limit:       rate.Limit(ratePerSecond),
+		burst:       burst,
+		idleTimeout: idleTimeout,
+		buckets:     make(map[string]*rateLimitBucket),
+		lastSweep:   time.Now(),
+	}
+}
+
+func (s *memoryRateLimitStore) Take(key string) (bool, time.Duration) {
+	now := time.Now()
+
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) >= s.idleTimeout {
+		s.sweep(now)
+	}
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{limiter: rate.NewLimiter(s.limit, s.burst)}
+		s.buckets[key] = bucket
+	}
+	bucket.lastSeen = now
+	s.mu.Unlock()
+
+	reservation := bucket.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+
+	return true, 0
+}
+
+// sweep removes the buckets unused for longer than idleTimeout. It must be called with mu held.
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.lastSeen) > s.idleTimeout {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.lastSweep = now
+}
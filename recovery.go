@@ -0,0 +1,59 @@
+package httpbara
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+	"io"
+	"runtime/debug"
+)
+
+// RecoverHandler is called by the recovery middleware of the base Gin engine with the value a handler panicked with.
+// It is expected to write a response and abort the context.
+type RecoverHandler func(ctx *gin.Context, recovered any)
+
+// RecoveryOpt configures the recovery middleware set up via WithRecovery.
+type RecoveryOpt func(*params)
+
+// WithRecoveryStack makes the recovery middleware log the stack trace of the panic next to the recovered value.
+func WithRecoveryStack() RecoveryOpt {
+	return func(params *params) {
+		params.recoveryStack = true
+	}
+}
+
+// recovery returns the recovery middleware of the base Gin engine. It logs the panic via the configured Logger
+// instead of Gin's error writer and passes the recovered value to the configured RecoverHandler.
+//
+// As the panic unwinds through the whole chain first, middlewares deferring work on the way out (such as the
+// otel middleware of httpbaratelemetry) see it before the response is rendered.
+func (c *core) recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(ctx *gin.Context, recovered any) {
+		args := []any{"method", ctx.Request.Method, "route", ctx.FullPath(), "panic", recovered}
+		if c.recoveryStack {
+			args = append(args, "stack", string(debug.Stack()))
+		}
+
+		c.log.Error("handler panicked", args...)
+
+		c.recoverHandler(ctx, recovered)
+	})
+}
+
+// defaultRecoverHandler renders the value a handler panicked with through the casual error responder, encoded per
+// content negotiation, and aborts the context. An error that is (or wraps) a casual.HttpError keeps its status code, any
+// other value is rendered as casual.ErrInternalServerError so that panic details do not leak into responses.
+func (c *core) defaultRecoverHandler(ctx *gin.Context, recovered any) {
+	err := error(casual.ErrInternalServerError)
+
+	if recoveredErr, ok := recovered.(error); ok {
+		var httpErr casual.HttpError
+		if errors.As(recoveredErr, &httpErr) {
+			err = recoveredErr
+		}
+	}
+
+	rcb := c.getResponseCallback(ctx)
+	rcb(c.casualResponseErrorHandler(err, languageParams(ctx)...))
+	ctx.Abort()
+}
@@ -0,0 +1,105 @@
+package httpbara_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+	"testing"
+)
+
+type panicHandlerDescriber struct {
+	Panic httpbara.Route `route:"GET /panic"`
+}
+
+type panicHandler struct {
+	panicHandlerDescriber
+
+	recovered any
+}
+
+func (h *panicHandler) Panic(ctx *gin.Context) {
+	panic(h.recovered)
+}
+
+func TestDefaultRecoverHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		recovered any
+		status    int
+		message   string
+	}{
+		{
+			name:      "http error",
+			recovered: casual.ErrUnauthorized,
+			status:    http.StatusUnauthorized,
+			message:   "unauthorized",
+		},
+		{
+			name:      "wrapped http error",
+			recovered: fmt.Errorf("loading session: %w", casual.ErrUnauthorized),
+			status:    http.StatusUnauthorized,
+			message:   "unauthorized",
+		},
+		{
+			name:      "http error with frontend message",
+			recovered: casual.NewHTTPErrorFromError(http.StatusConflict, errors.New("duplicate key"), "already exists"),
+			status:    http.StatusConflict,
+			message:   "already exists",
+		},
+		{
+			name:      "plain error",
+			recovered: errors.New("database is down"),
+			status:    http.StatusInternalServerError,
+			message:   "internal server error",
+		},
+		{
+			name:      "non error value",
+			recovered: "boom",
+			status:    http.StatusInternalServerError,
+			message:   "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &panicHandler{recovered: tt.recovered})})
+
+			rec := serve(h, http.MethodGet, "/panic", nil, map[string]string{"Accept": "application/json"})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+
+			var resp casual.HttpErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if resp.Error == nil || resp.Error.Message != tt.message {
+				t.Fatalf("error = %+v, want message %q", resp.Error, tt.message)
+			}
+		})
+	}
+}
+
+func TestWithRecoverHandler(t *testing.T) {
+	var recovered any
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &panicHandler{recovered: "boom"})},
+		httpbara.WithRecoverHandler(func(ctx *gin.Context, value any) {
+			recovered = value
+			ctx.AbortWithStatus(http.StatusServiceUnavailable)
+		}),
+	)
+
+	rec := serve(h, http.MethodGet, "/panic", nil, nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	if recovered != "boom" {
+		t.Fatalf("recovered = %v, want boom", recovered)
+	}
+}
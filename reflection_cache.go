@@ -0,0 +1,105 @@
+package httpbara
+
+import (
+	"github.com/gin-gonic/gin"
+	"reflect"
+	"sync"
+)
+
+var (
+	// reflectionFieldsCache holds the *reflectionFields scanned for a handler struct type, keyed by reflect.Type.
+	reflectionFieldsCache sync.Map
+
+	// handlerMethodsCache holds the []handlerMethod found on a handler struct type, keyed by reflect.Type.
+	handlerMethodsCache sync.Map
+)
+
+// reflectionFields is the result of getAllReflectionFieldsRecursive for a handler struct type.
+// Only type information is kept, the handlers bound to an instance are always read from the instance.
+//
+// Fields:
+// - `fields`: The flattened struct fields, shared between the instances of the type and never modified.
+// - `funcFieldIndexes`: The index paths of the exported `gin.HandlerFunc` fields, keyed by field name.
+// - `dynamic`: Whether the scan followed an embedded interface, in which case it is not cached.
+type reflectionFields struct {
+	fields           []reflect.StructField
+	funcFieldIndexes map[string][]int
+	dynamic          bool
+}
+
+// funcFieldValues reads the non-nil `gin.HandlerFunc` fields of rv. Fields behind a nil embedded pointer are skipped.
+func (rf *reflectionFields) funcFieldValues(rv reflect.Value) map[string]gin.HandlerFunc {
+	funcFields := make(map[string]gin.HandlerFunc)
+	if len(rf.funcFieldIndexes) == 0 {
+		return funcFields
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return funcFields
+		}
+
+		rv = rv.Elem()
+	}
+
+	for name, index := range rf.funcFieldIndexes {
+		field, err := rv.FieldByIndexErr(index)
+		if err != nil || field.IsNil() {
+			continue
+		}
+
+		funcFields[name] = field.Interface().(gin.HandlerFunc)
+	}
+
+	return funcFields
+}
+
+// handlerKind is the kind of handler an exported method of a handler struct can be bound as.
+type handlerKind int
+
+const (
+	ginHandlerKind handlerKind = iota
+	casualHandlerKind
+	websocketHandlerKind
+	staticHandlerKind
+	parameterizedMiddlewareKind
+)
+
+// handlerMethod is an exported method of a handler struct type with the signature of a Gin handler,
+// a casual handler, a websocket handler, a static handler or a parameterized middleware.
+type handlerMethod struct {
+	reflect.Method
+
+	kind handlerKind
+}
+
+// handlerMethodsOf returns the handler methods of t, scanning the method set only once per type.
+func handlerMethodsOf(t reflect.Type) []handlerMethod {
+	if cached, ok := handlerMethodsCache.Load(t); ok {
+		return cached.([]handlerMethod)
+	}
+
+	methods := make([]handlerMethod, 0)
+	for i := 0; i < t.NumMethod(); i++ {
+		method := t.Method(i)
+		if !method.IsExported() {
+			continue
+		}
+
+		if isSimpleGinHandler(method.Type) {
+			methods = append(methods, handlerMethod{Method: method, kind: ginHandlerKind})
+		} else if isParameterizedMiddleware(method.Type) {
+			methods = append(methods, handlerMethod{Method: method, kind: parameterizedMiddlewareKind})
+		} else if isStaticHandler(method.Type) {
+			methods = append(methods, handlerMethod{Method: method, kind: staticHandlerKind})
+		} else if isWebsocketHandler(method.Type) {
+			methods = append(methods, handlerMethod{Method: method, kind: websocketHandlerKind})
+		} else if isCasualHandler(method.Type) {
+			methods = append(methods, handlerMethod{Method: method, kind: casualHandlerKind})
+		}
+	}
+
+	handlerMethodsCache.Store(t, methods)
+
+	return methods
+}
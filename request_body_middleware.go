@@ -0,0 +1,147 @@
+package httpbara
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultRequestBodyMaxBytes = 10 << 20
+
+var (
+	// ErrRequestBodyTooLarge is answered by the request body middleware when the (decompressed) body exceeds the cap.
+	ErrRequestBodyTooLarge = casual.NewHTTPErrorFromMessage(http.StatusRequestEntityTooLarge, "request body too large")
+
+	// ErrMalformedRequestBody is answered by the request body middleware when the body cannot be decompressed.
+	ErrMalformedRequestBody = casual.NewHTTPErrorFromMessage(http.StatusBadRequest, "malformed compressed request body")
+
+	// ErrUnsupportedContentEncoding is answered by the request body middleware for content encodings it cannot decode.
+	ErrUnsupportedContentEncoding = casual.NewHTTPErrorFromMessage(http.StatusUnsupportedMediaType, "unsupported content encoding")
+)
+
+// RequestBodyConfig configures the middleware created by NewRequestBodyMiddleware.
+//
+// Fields:
+// - `MaxBytes`: The maximum size of the body after decompression. Defaults to 10 MiB.
+// - `DisableDecompression`: Rejects compressed bodies with 415 instead of decompressing them.
+type RequestBodyConfig struct {
+	MaxBytes             int64
+	DisableDecompression bool
+}
+
+type requestBodyMiddlewareDescriber struct {
+	Middleware Middleware `middleware:"requestBody"`
+}
+
+type requestBodyMiddleware struct {
+	requestBodyMiddlewareDescriber
+
+	cfg RequestBodyConfig
+}
+
+// NewRequestBodyMiddleware creates a middleware named "requestBody" that decompresses request bodies sent with
+// `Content-Encoding: gzip` or `deflate` and caps their size after decompression, which protects handlers from
+// decompression bombs. The body is read up to the cap and restored uncompressed, so binding works as usual.
+//
+// Bodies over the cap are answered with ErrRequestBodyTooLarge (413), bodies that cannot be decompressed with
+// ErrMalformedRequestBody (400) and other content encodings with ErrUnsupportedContentEncoding (415).
+//
+// **Example:**
+// ```go
+//
+//	requestBody, _ := httpbara.NewRequestBodyMiddleware(httpbara.RequestBodyConfig{MaxBytes: 1 << 20})
+//	engine, _ := httpbara.New(handlers, httpbara.WithRootMiddlewares(requestBody))
+//
+// ```
+func NewRequestBodyMiddleware(cfg RequestBodyConfig) (*Handler, error) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultRequestBodyMaxBytes
+	}
+
+	rbm := requestBodyMiddleware{
+		cfg: cfg,
+	}
+
+	return AsHandler(&rbm)
+}
+
+func (rbm *requestBodyMiddleware) Middleware(ctx *gin.Context) {
+	if ctx.Request.Body == nil || ctx.Request.Body == http.NoBody {
+		ctx.Next()
+		return
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(ctx.GetHeader("Content-Encoding")))
+	if encoding == "" || encoding == "identity" {
+		if ctx.Request.ContentLength > rbm.cfg.MaxBytes {
+			rbm.fail(ctx, ErrRequestBodyTooLarge)
+			return
+		}
+	} else if rbm.cfg.DisableDecompression {
+		rbm.fail(ctx, ErrUnsupportedContentEncoding)
+		return
+	}
+
+	body, err := rbm.decoder(ctx.Request.Body, encoding)
+	if err != nil {
+		rbm.fail(ctx, err)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, rbm.cfg.MaxBytes+1))
+	if err != nil {
+		if encoding != "" && encoding != "identity" {
+			err = ErrMalformedRequestBody
+		}
+
+		rbm.fail(ctx, err)
+		return
+	}
+
+	if int64(len(data)) > rbm.cfg.MaxBytes {
+		rbm.fail(ctx, ErrRequestBodyTooLarge)
+		return
+	}
+
+	_ = ctx.Request.Body.Close()
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(data))
+	ctx.Request.ContentLength = int64(len(data))
+	ctx.Request.Header.Del("Content-Encoding")
+	ctx.Request.Header.Set("Content-Length", strconv.Itoa(len(data)))
+
+	ctx.Next()
+}
+
+// decoder returns a reader decompressing body according to the content encoding.
+func (rbm *requestBodyMiddleware) decoder(body io.Reader, encoding string) (io.Reader, error) {
+	switch encoding {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, ErrMalformedRequestBody
+		}
+
+		return reader, nil
+	case "deflate":
+		reader, err := zlib.NewReader(body)
+		if err != nil {
+			return nil, ErrMalformedRequestBody
+		}
+
+		return reader, nil
+	default:
+		return nil, ErrUnsupportedContentEncoding
+	}
+}
+
+func (rbm *requestBodyMiddleware) fail(ctx *gin.Context, err error) {
+	casual.Fail(ctx, err)
+}
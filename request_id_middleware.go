@@ -0,0 +1,119 @@
+package httpbara
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultRequestIDHeader is the header read and written by the request ID middleware by default.
+	DefaultRequestIDHeader = "X-Request-ID"
+
+	requestIDKey = "requestId"
+
+	maxRequestIDLength = 128
+)
+
+// requestIDContextKey is the request context key of the request ID.
+type requestIDContextKey struct{}
+
+type requestIDOpts struct {
+	header    string
+	generator func() string
+}
+
+type RequestIDOpt func(*requestIDOpts)
+
+// WithRequestIDHeader sets the header carrying the request ID, DefaultRequestIDHeader by default.
+func WithRequestIDHeader(header string) RequestIDOpt {
+	return func(opts *requestIDOpts) {
+		opts.header = header
+	}
+}
+
+// WithRequestIDGenerator sets the function generating IDs for requests without one, random UUIDs by default.
+func WithRequestIDGenerator(generator func() string) RequestIDOpt {
+	return func(opts *requestIDOpts) {
+		opts.generator = generator
+	}
+}
+
+type requestIDMiddlewareDescriber struct {
+	Middleware Middleware `middleware:"requestId"`
+}
+
+type requestIDMiddleware struct {
+	requestIDMiddlewareDescriber
+
+	opts requestIDOpts
+}
+
+// NewRequestIDMiddleware creates a middleware named "requestId" that takes the request ID from the
+// `X-Request-ID` header, or generates one when it is missing or longer than 128 characters, and echoes it
+// in the response header. The ID is stored in the gin context and in the request context, so casual handlers
+// read it with RequestIDFromContext, and the access log middleware logs it as the "requestId" field.
+//
+// **Example:**
+// ```go
+//
+//	requestID, _ := httpbara.NewRequestIDMiddleware()
+//	engine, _ := httpbara.New(handlers, httpbara.WithRootMiddlewares(requestID, accessLog))
+//
+//	func (h *Handler) Create(ctx context.Context, req *CreateRequest) (*Product, error) {
+//	    h.log.Info("creating product", "requestId", httpbara.RequestIDFromContext(ctx))
+//	    // ...
+//	}
+//
+// ```
+func NewRequestIDMiddleware(opts ...RequestIDOpt) (*Handler, error) {
+	rim := requestIDMiddleware{
+		opts: requestIDOpts{
+			header:    DefaultRequestIDHeader,
+			generator: newUUID,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&rim.opts)
+	}
+
+	return AsHandler(&rim)
+}
+
+func (rim *requestIDMiddleware) Middleware(ctx *gin.Context) {
+	id := ctx.GetHeader(rim.opts.header)
+	if id == "" || len(id) > maxRequestIDLength {
+		id = rim.opts.generator()
+	}
+
+	ctx.Set(requestIDKey, id)
+	ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), requestIDContextKey{}, id))
+	ctx.Header(rim.opts.header, id)
+
+	ctx.Next()
+}
+
+// RequestIDFromContext returns the request ID set by the request ID middleware from a request context
+// or a *gin.Context, or an empty string when the middleware was not applied.
+func RequestIDFromContext(ctx context.Context) string {
+	if gctx, ok := ctx.(*gin.Context); ok {
+		return gctx.GetString(requestIDKey)
+	}
+
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+
+	return id
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,138 @@
+package httpbara
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultMaxURLLength   = 8 << 10
+	defaultMaxQueryParams = 100
+	defaultMaxHeaderBytes = 32 << 10
+)
+
+var (
+	// ErrURLTooLong is answered by the request limits middleware when the request URI exceeds its limit.
+	ErrURLTooLong = casual.NewHTTPErrorFromMessage(http.StatusRequestURITooLong, "request uri too long")
+
+	// ErrTooManyQueryParams is answered by the request limits middleware when the query has too many parameters.
+	ErrTooManyQueryParams = casual.NewHTTPErrorFromMessage(http.StatusBadRequest, "too many query parameters")
+
+	// ErrHeadersTooLarge is answered by the request limits middleware when the request headers exceed their limit.
+	ErrHeadersTooLarge = casual.NewHTTPErrorFromMessage(http.StatusRequestHeaderFieldsTooLarge, "request header fields too large")
+)
+
+// RequestLimitsConfig configures the middleware created by NewRequestLimitsMiddleware. A zero limit uses
+// its default and a negative limit disables the check.
+//
+// Fields:
+// - `MaxURLLength`: The maximum length of the request URI (path and query) in bytes. Defaults to 8 KiB.
+// - `MaxQueryParams`: The maximum number of query parameters, counting repeated keys. Defaults to 100.
+// - `MaxHeaderBytes`: The maximum total size of the header names and values in bytes. Defaults to 32 KiB.
+type RequestLimitsConfig struct {
+	MaxURLLength   int
+	MaxQueryParams int
+	MaxHeaderBytes int
+}
+
+type requestLimitsMiddlewareDescriber struct {
+	Middleware Middleware `middleware:"requestLimits"`
+}
+
+type requestLimitsMiddleware struct {
+	requestLimitsMiddlewareDescriber
+
+	cfg RequestLimitsConfig
+}
+
+// NewRequestLimitsMiddleware creates a middleware named "requestLimits" that rejects requests with oversized
+// URIs, queries or headers before the handler binds them, protecting the binder from pathological inputs.
+//
+// Requests over a limit are answered with ErrURLTooLong (414), ErrTooManyQueryParams (400) or
+// ErrHeadersTooLarge (431). Register it with WithRootMiddlewares so it runs before the other middlewares.
+// The header limit complements the `MaxHeaderBytes` of the server (see WithHTTPServer), which bounds
+// what is read from the connection at all.
+//
+// **Example:**
+// ```go
+//
+//	limits, _ := httpbara.NewRequestLimitsMiddleware(httpbara.RequestLimitsConfig{MaxQueryParams: 20})
+//	engine, _ := httpbara.New(handlers, httpbara.WithRootMiddlewares(limits))
+//
+// ```
+func NewRequestLimitsMiddleware(cfg RequestLimitsConfig) (*Handler, error) {
+	if cfg.MaxURLLength == 0 {
+		cfg.MaxURLLength = defaultMaxURLLength
+	}
+
+	if cfg.MaxQueryParams == 0 {
+		cfg.MaxQueryParams = defaultMaxQueryParams
+	}
+
+	if cfg.MaxHeaderBytes == 0 {
+		cfg.MaxHeaderBytes = defaultMaxHeaderBytes
+	}
+
+	rlm := requestLimitsMiddleware{
+		cfg: cfg,
+	}
+
+	return AsHandler(&rlm)
+}
+
+func (rlm *requestLimitsMiddleware) Middleware(ctx *gin.Context) {
+	if rlm.cfg.MaxURLLength > 0 && len(rlm.requestURI(ctx.Request)) > rlm.cfg.MaxURLLength {
+		casual.Fail(ctx, ErrURLTooLong)
+		return
+	}
+
+	if rlm.cfg.MaxQueryParams > 0 && countQueryParams(ctx.Request.URL.RawQuery) > rlm.cfg.MaxQueryParams {
+		casual.Fail(ctx, ErrTooManyQueryParams)
+		return
+	}
+
+	if rlm.cfg.MaxHeaderBytes > 0 && headerBytes(ctx.Request.Header) > rlm.cfg.MaxHeaderBytes {
+		casual.Fail(ctx, ErrHeadersTooLarge)
+		return
+	}
+
+	ctx.Next()
+}
+
+// requestURI returns the request URI as sent by the client, or rebuilt from the URL for requests
+// that were not read from a connection (e.g. in tests).
+func (rlm *requestLimitsMiddleware) requestURI(r *http.Request) string {
+	if r.RequestURI != "" {
+		return r.RequestURI
+	}
+
+	return r.URL.RequestURI()
+}
+
+// countQueryParams counts the parameters of a raw query without decoding it.
+func countQueryParams(rawQuery string) int {
+	count := 0
+	for rawQuery != "" {
+		var part string
+		part, rawQuery, _ = strings.Cut(rawQuery, "&")
+		if part != "" {
+			count++
+		}
+	}
+
+	return count
+}
+
+// headerBytes returns the total size of the header names and values.
+func headerBytes(header http.Header) int {
+	size := 0
+	for name, values := range header {
+		for _, value := range values {
+			size += len(name) + len(value)
+		}
+	}
+
+	return size
+}
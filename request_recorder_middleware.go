@@ -0,0 +1,198 @@
+package httpbara
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrRecorderSinkNotSet = errors.New("recorder sink is not set")
+)
+
+// RedactedValue replaces the values of redacted headers in recorded requests.
+const RedactedValue = "[REDACTED]"
+
+// RecordedRequest is a snapshot of an incoming request that can be replayed later, e.g. against a staging environment.
+//
+// Fields:
+// - `Time`: The time the request was received.
+// - `Method`: The HTTP method.
+// - `URL`: The request URI including the query string, e.g. "/users/42?expand=posts".
+// - `Host`: The host the request was sent to.
+// - `Header`: The request headers with redacted values replaced by RedactedValue.
+// - `Body`: The request body, truncated to the configured maximum size.
+// - `Truncated`: Whether the body was truncated.
+type RecordedRequest struct {
+	Time      time.Time
+	Method    string
+	URL       string
+	Host      string
+	Header    http.Header
+	Body      []byte
+	Truncated bool
+}
+
+// RecorderSink stores recorded requests. Record is called synchronously from the request goroutine,
+// so implementations should hand slow work (files, network) off to a background worker.
+type RecorderSink interface {
+	Record(req *RecordedRequest)
+}
+
+type requestRecorderOpts struct {
+	sampleRate      float64
+	maxBodySize     int64
+	redactedHeaders []string
+	redactors       []func(req *RecordedRequest)
+}
+
+type RequestRecorderOpt func(*requestRecorderOpts)
+
+// WithRecorderSampleRate sets the share of requests that are recorded, from 0 (none) to 1 (all, the default).
+func WithRecorderSampleRate(rate float64) RequestRecorderOpt {
+	return func(opts *requestRecorderOpts) {
+		opts.sampleRate = rate
+	}
+}
+
+// WithRecorderMaxBodySize limits the number of body bytes kept in a recorded request (1 MiB by default),
+// 0 records no body. The request passed to the handler always keeps its full body.
+func WithRecorderMaxBodySize(size int64) RequestRecorderOpt {
+	return func(opts *requestRecorderOpts) {
+		opts.maxBodySize = size
+	}
+}
+
+// WithRecorderRedactedHeaders replaces the default list of redacted headers
+// (Authorization, Proxy-Authorization, Cookie and Set-Cookie).
+func WithRecorderRedactedHeaders(headers ...string) RequestRecorderOpt {
+	return func(opts *requestRecorderOpts) {
+		opts.redactedHeaders = headers
+	}
+}
+
+// WithRecorderRedactor adds a function that can modify a recorded request before it reaches the sink,
+// e.g. to mask secrets in the body.
+func WithRecorderRedactor(redactor func(req *RecordedRequest)) RequestRecorderOpt {
+	return func(opts *requestRecorderOpts) {
+		opts.redactors = append(opts.redactors, redactor)
+	}
+}
+
+type requestRecorderMiddlewareDescriber struct {
+	Middleware Middleware `middleware:"requestRecorder"`
+}
+
+type requestRecorderMiddleware struct {
+	requestRecorderMiddlewareDescriber
+
+	sink RecorderSink
+	opts requestRecorderOpts
+}
+
+// NewRequestRecorderMiddleware creates a middleware named "requestRecorder" that records sampled requests
+// (method, URL, headers and body) to the given sink for later replay. The body is buffered and restored,
+// so handlers read it as usual.
+//
+// **Example:**
+// ```go
+//
+//	recorder, _ := httpbara.NewRequestRecorderMiddleware(sink,
+//	    httpbara.WithRecorderSampleRate(0.01),
+//	    httpbara.WithRecorderRedactedHeaders("Authorization", "X-Api-Key"),
+//	)
+//	engine, _ := httpbara.New(handlers, httpbara.WithRootMiddlewares(recorder))
+//
+// ```
+func NewRequestRecorderMiddleware(sink RecorderSink, opts ...RequestRecorderOpt) (*Handler, error) {
+	if sink == nil {
+		return nil, ErrRecorderSinkNotSet
+	}
+
+	rrm := requestRecorderMiddleware{
+		sink: sink,
+		opts: requestRecorderOpts{
+			sampleRate:      1,
+			maxBodySize:     1 << 20,
+			redactedHeaders: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"},
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&rrm.opts)
+	}
+
+	if rrm.opts.maxBodySize < 0 {
+		return nil, fmt.Errorf("invalid recorder max body size: %d", rrm.opts.maxBodySize)
+	}
+
+	return AsHandler(&rrm)
+}
+
+func (rrm *requestRecorderMiddleware) Middleware(ctx *gin.Context) {
+	if rrm.opts.sampleRate < 1 && rand.Float64() >= rrm.opts.sampleRate {
+		ctx.Next()
+		return
+	}
+
+	req := &RecordedRequest{
+		Time:   time.Now(),
+		Method: ctx.Request.Method,
+		URL:    ctx.Request.URL.RequestURI(),
+		Host:   ctx.Request.Host,
+		Header: ctx.Request.Header.Clone(),
+	}
+
+	if ctx.Request.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, rrm.opts.maxBodySize+1))
+		if int64(len(body)) > rrm.opts.maxBodySize {
+			req.Body = body[:rrm.opts.maxBodySize]
+			req.Truncated = true
+		} else {
+			req.Body = body
+		}
+
+		// Replay the buffered bytes followed by the unread remainder of the body.
+		ctx.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: io.MultiReader(bytes.NewReader(body), &errReader{err: err, r: ctx.Request.Body}),
+			Closer: ctx.Request.Body,
+		}
+	}
+
+	for _, header := range rrm.opts.redactedHeaders {
+		if _, ok := req.Header[http.CanonicalHeaderKey(header)]; ok {
+			req.Header.Set(header, RedactedValue)
+		}
+	}
+
+	for _, redactor := range rrm.opts.redactors {
+		redactor(req)
+	}
+
+	rrm.sink.Record(req)
+
+	ctx.Next()
+}
+
+// errReader returns err, if any, before reading from r, so that a read error hit while buffering
+// the body is reported to the handler instead of being swallowed.
+type errReader struct {
+	err error
+	r   io.Reader
+}
+
+func (e *errReader) Read(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	return e.r.Read(p)
+}
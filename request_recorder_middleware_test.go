@@ -0,0 +1,163 @@
+package httpbara_test
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type recordingSink struct {
+	requests []*httpbara.RecordedRequest
+}
+
+func (s *recordingSink) Record(req *httpbara.RecordedRequest) {
+	s.requests = append(s.requests, req)
+}
+
+type echoHandlerDescriber struct {
+	Echo httpbara.Route `route:"POST /echo"`
+}
+
+type echoHandler struct {
+	echoHandlerDescriber
+}
+
+func (h *echoHandler) Echo(ctx *gin.Context) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "text/plain", body)
+}
+
+func TestRequestRecorderMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []httpbara.RequestRecorderOpt
+		body          string
+		headers       map[string]string
+		recorded      bool
+		wantBody      string
+		wantTruncated bool
+		wantHeader    map[string]string
+	}{
+		{
+			name:     "full body",
+			body:     "hello",
+			recorded: true,
+			wantBody: "hello",
+		},
+		{
+			name:          "truncated body",
+			opts:          []httpbara.RequestRecorderOpt{httpbara.WithRecorderMaxBodySize(3)},
+			body:          "hello",
+			recorded:      true,
+			wantBody:      "hel",
+			wantTruncated: true,
+		},
+		{
+			name:          "no body",
+			opts:          []httpbara.RequestRecorderOpt{httpbara.WithRecorderMaxBodySize(0)},
+			body:          "hello",
+			recorded:      true,
+			wantTruncated: true,
+		},
+		{
+			name:     "default redacted headers",
+			body:     "hello",
+			headers:  map[string]string{"Authorization": "Bearer secret", "X-Api-Key": "key"},
+			recorded: true,
+			wantBody: "hello",
+			wantHeader: map[string]string{
+				"Authorization": httpbara.RedactedValue,
+				"X-Api-Key":     "key",
+			},
+		},
+		{
+			name:     "custom redacted headers",
+			opts:     []httpbara.RequestRecorderOpt{httpbara.WithRecorderRedactedHeaders("X-Api-Key")},
+			body:     "hello",
+			headers:  map[string]string{"Authorization": "Bearer secret", "X-Api-Key": "key"},
+			recorded: true,
+			wantBody: "hello",
+			wantHeader: map[string]string{
+				"Authorization": "Bearer secret",
+				"X-Api-Key":     httpbara.RedactedValue,
+			},
+		},
+		{
+			name: "redactor",
+			opts: []httpbara.RequestRecorderOpt{httpbara.WithRecorderRedactor(func(req *httpbara.RecordedRequest) {
+				req.Body = bytes.ReplaceAll(req.Body, []byte("secret"), []byte("******"))
+			})},
+			body:     "password=secret",
+			recorded: true,
+			wantBody: "password=******",
+		},
+		{
+			name: "not sampled",
+			opts: []httpbara.RequestRecorderOpt{httpbara.WithRecorderSampleRate(0)},
+			body: "hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			recorder, err := httpbara.NewRequestRecorderMiddleware(sink, tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to create middleware: %v", err)
+			}
+
+			h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &echoHandler{})}, httpbara.WithRootMiddlewares(recorder))
+
+			rec := serve(h, http.MethodPost, "/echo?q=1", strings.NewReader(tt.body), tt.headers)
+			if rec.Code != http.StatusOK || rec.Body.String() != tt.body {
+				t.Fatalf("response = %d %q, want %d %q", rec.Code, rec.Body.String(), http.StatusOK, tt.body)
+			}
+
+			if !tt.recorded {
+				if len(sink.requests) != 0 {
+					t.Fatalf("recorded %d requests, want none", len(sink.requests))
+				}
+
+				return
+			}
+
+			if len(sink.requests) != 1 {
+				t.Fatalf("recorded %d requests, want 1", len(sink.requests))
+			}
+
+			req := sink.requests[0]
+			if req.Method != http.MethodPost || req.URL != "/echo?q=1" {
+				t.Fatalf("recorded %s %s, want POST /echo?q=1", req.Method, req.URL)
+			}
+
+			if string(req.Body) != tt.wantBody || req.Truncated != tt.wantTruncated {
+				t.Fatalf("recorded body = %q (truncated %v), want %q (truncated %v)", req.Body, req.Truncated, tt.wantBody, tt.wantTruncated)
+			}
+
+			for key, value := range tt.wantHeader {
+				if got := req.Header.Get(key); got != value {
+					t.Fatalf("recorded header %s = %q, want %q", key, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRequestRecorderMiddlewareInvalid(t *testing.T) {
+	if _, err := httpbara.NewRequestRecorderMiddleware(nil); err != httpbara.ErrRecorderSinkNotSet {
+		t.Fatalf("err = %v, want %v", err, httpbara.ErrRecorderSinkNotSet)
+	}
+
+	if _, err := httpbara.NewRequestRecorderMiddleware(&recordingSink{}, httpbara.WithRecorderMaxBodySize(-1)); err == nil {
+		t.Fatal("expected an error for a negative max body size")
+	}
+}
@@ -0,0 +1,77 @@
+package httpbara
+
+import (
+	"github.com/gin-gonic/gin"
+	"strings"
+	"time"
+)
+
+// RouteOpt configures a route created with NewRoute.
+type RouteOpt func(*Route)
+
+// WithRouteMiddlewares applies the given middlewares to the route, as the `middlewares` tag does.
+func WithRouteMiddlewares(middlewares ...string) RouteOpt {
+	return func(route *Route) {
+		route.middlewares = append(route.middlewares, parseMiddlewaresTag(strings.Join(middlewares, ","))...)
+	}
+}
+
+// WithRouteGroup places the route in the group with the given name, as the `group` tag does.
+func WithRouteGroup(group string) RouteOpt {
+	return func(route *Route) {
+		route.group = parseGroupReference(group)
+	}
+}
+
+// WithRouteTimeout cancels the request context of the route after timeout, as the `timeout` tag does.
+func WithRouteTimeout(timeout time.Duration) RouteOpt {
+	return func(route *Route) {
+		route.timeout = timeout
+	}
+}
+
+// WithRouteMeta attaches a key/value pair to the route, as the `meta` tag does, see RouteMetadata.
+func WithRouteMeta(key, value string) RouteOpt {
+	return func(route *Route) {
+		if route.metadata == nil {
+			route.metadata = make(map[string]string)
+		}
+
+		route.metadata[key] = value
+	}
+}
+
+// NewRoute creates a route outside of the handler tags, e.g. for dynamically generated endpoints.
+// Add it to a handler with Handler.AddRoute; it is registered by New like the routes declared by tags,
+// with its group prefix and middlewares. The method is matched case-insensitively, "ANY" matches every method.
+//
+// **Example:**
+// ```go
+//
+//	handler, _ := httpbara.AsHandler(&Handlers{})
+//	handler.AddRoute(httpbara.NewRoute(http.MethodGet, "/reports/"+name, reportHandler(name),
+//	    httpbara.WithRouteGroup("v3"),
+//	    httpbara.WithRouteMiddlewares("auth"),
+//	))
+//
+// ```
+func NewRoute(method, path string, handler gin.HandlerFunc, opts ...RouteOpt) *Route {
+	method = strings.ToUpper(method)
+	route := &Route{
+		name:    method + " " + path,
+		method:  method,
+		path:    path,
+		handler: handler,
+	}
+
+	for _, opt := range opts {
+		opt(route)
+	}
+
+	return route
+}
+
+// AddRoute adds a route created with NewRoute to the handler. It must be called before the handler is passed to New.
+func (h *Handler) AddRoute(route *Route) {
+	h.routes = append(h.routes, route)
+}
@@ -0,0 +1,92 @@
+package httpbara
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"maps"
+)
+
+const routeMetadataKey = "routeMetadata"
+
+// routeMetadataContextKey is the request context key of the route metadata.
+type routeMetadataContextKey struct{}
+
+// WithRouteMetadata attaches metadata to the route registered for method and path, e.g.
+// `WithRouteMetadata("GET", "/api/v3/products/:id", map[string]string{"cache": "public"})`. The path is the
+// registered path including group prefixes, as listed by Engine.Routes. The metadata is merged over the `meta`
+// tag of the route, so registered values win. It is read at request time with RouteMetadata, which lets
+// middlewares take per-route configuration such as cache policies or auth scopes.
+func WithRouteMetadata(method, path string, metadata map[string]string) ParamsCb {
+	return func(params *params) error {
+		if params.routeMetadata == nil {
+			params.routeMetadata = make(map[string]map[string]string)
+		}
+
+		key := method + " " + path
+		if params.routeMetadata[key] == nil {
+			params.routeMetadata[key] = make(map[string]string)
+		}
+
+		maps.Copy(params.routeMetadata[key], metadata)
+
+		return nil
+	}
+}
+
+// RouteMetadata returns the metadata of the matched route, declared with the `meta` tag or WithRouteMetadata,
+// from a request context or a *gin.Context. It returns nil for routes without metadata. The returned map is
+// shared between requests and must not be modified.
+//
+// **Example:**
+// ```go
+//
+//	type OrderRoutes struct {
+//	    GetOrder Route `route:"GET /orders/:id" middlewares:"auth" meta:"scope=orders:read"`
+//	}
+//
+//	func (a *Auth) Auth(ctx *gin.Context) {
+//	    if !a.hasScope(ctx, httpbara.RouteMetadata(ctx)["scope"]) {
+//	        casual.Fail(ctx, casual.ErrUnauthorized)
+//	        return
+//	    }
+//
+//	    ctx.Next()
+//	}
+//
+// ```
+func RouteMetadata(ctx context.Context) map[string]string {
+	if gctx, ok := ctx.(*gin.Context); ok {
+		metadata, _ := gctx.Get(routeMetadataKey)
+		result, _ := metadata.(map[string]string)
+
+		return result
+	}
+
+	metadata, _ := ctx.Value(routeMetadataContextKey{}).(map[string]string)
+
+	return metadata
+}
+
+// routeMetadataOf merges the metadata registered for a route with WithRouteMetadata over its `meta` tag.
+func (c *core) routeMetadataOf(route *Route, path string) map[string]string {
+	registered := c.routeMetadata[route.method+" "+path]
+	if len(registered) == 0 {
+		return route.metadata
+	}
+
+	metadata := make(map[string]string, len(route.metadata)+len(registered))
+	maps.Copy(metadata, route.metadata)
+	maps.Copy(metadata, registered)
+
+	return metadata
+}
+
+// routeMetadataHandler makes the metadata of a route available to RouteMetadata.
+func routeMetadataHandler(metadata map[string]string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(routeMetadataKey, metadata)
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), routeMetadataContextKey{}, metadata))
+
+		ctx.Next()
+	}
+}
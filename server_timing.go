@@ -0,0 +1,95 @@
+package httpbara
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strings"
+	"time"
+)
+
+const serverTimingKey = "serverTimings"
+
+// serverTiming is a single metric of the `Server-Timing` header.
+type serverTiming struct {
+	name     string
+	duration time.Duration
+}
+
+// serverTimingWriter sets the `Server-Timing` header right before the response headers are written,
+// since the header cannot be changed once the handler has started writing the body.
+type serverTimingWriter struct {
+	gin.ResponseWriter
+
+	ctx     *gin.Context
+	start   time.Time
+	written bool
+}
+
+func (w *serverTimingWriter) setHeader() {
+	if w.written || w.ResponseWriter.Written() {
+		return
+	}
+	w.written = true
+
+	metrics := []string{fmt.Sprintf("total;dur=%.3f", float64(time.Since(w.start).Microseconds())/1000)}
+	if timings, ok := w.ctx.Get(serverTimingKey); ok {
+		for _, timing := range *timings.(*[]serverTiming) {
+			metrics = append(metrics, fmt.Sprintf("%s;dur=%.3f", timing.name, float64(timing.duration.Microseconds())/1000))
+		}
+	}
+
+	w.Header().Set("Server-Timing", strings.Join(metrics, ", "))
+}
+
+func (w *serverTimingWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *serverTimingWriter) Write(data []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *serverTimingWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *serverTimingWriter) Flush() {
+	w.setHeader()
+	w.ResponseWriter.Flush()
+}
+
+// serverTimingMiddleware measures the time spent handling the request until the response headers are written
+// and reports it as the `total` metric of the `Server-Timing` header, e.g. `Server-Timing: total;dur=12.345`.
+// Durations are in milliseconds.
+func serverTimingMiddleware(ctx *gin.Context) {
+	timings := make([]serverTiming, 0)
+	ctx.Set(serverTimingKey, &timings)
+
+	writer := &serverTimingWriter{
+		ResponseWriter: ctx.Writer,
+		ctx:            ctx,
+		start:          time.Now(),
+	}
+	ctx.Writer = writer
+
+	ctx.Next()
+
+	writer.setHeader()
+	ctx.Writer = writer.ResponseWriter
+}
+
+// AddServerTiming adds a named duration, e.g. the time spent in a database call, to the `Server-Timing` header
+// written when WithServerTiming is enabled. Timings added after the response headers were written are dropped.
+// It does nothing when server timing is disabled.
+func AddServerTiming(ctx *gin.Context, name string, duration time.Duration) {
+	timings, ok := ctx.Get(serverTimingKey)
+	if !ok {
+		return
+	}
+
+	list := timings.(*[]serverTiming)
+	*list = append(*list, serverTiming{name: name, duration: duration})
+}
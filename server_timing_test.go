@@ -0,0 +1,82 @@
+package httpbara_test
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type serverTimingHandlerDescriber struct {
+	Plain  httpbara.Route `route:"GET /plain"`
+	DB     httpbara.Route `route:"GET /db"`
+	Empty  httpbara.Route `route:"GET /empty"`
+	Casual httpbara.Route `route:"GET /casual"`
+}
+
+type serverTimingHandler struct {
+	serverTimingHandlerDescriber
+}
+
+func (h *serverTimingHandler) Plain(ctx *gin.Context) {
+	ctx.String(http.StatusOK, "ok")
+}
+
+func (h *serverTimingHandler) DB(ctx *gin.Context) {
+	httpbara.AddServerTiming(ctx, "db", 1500*time.Microsecond)
+	httpbara.AddServerTiming(ctx, "cache", 250*time.Microsecond)
+	ctx.String(http.StatusOK, "ok")
+}
+
+func (h *serverTimingHandler) Empty(ctx *gin.Context) {
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *serverTimingHandler) Casual(ctx context.Context) (*methodStatusItem, error) {
+	return &methodStatusItem{ID: "1"}, nil
+}
+
+func TestWithServerTiming(t *testing.T) {
+	total := `total;dur=\d+\.\d{3}`
+
+	tests := []struct {
+		name   string
+		target string
+		opts   []httpbara.ParamsCb
+		header string
+	}{
+		{name: "disabled", target: "/plain"},
+		{name: "gin handler", target: "/plain", opts: []httpbara.ParamsCb{httpbara.WithServerTiming()}, header: `^` + total + `$`},
+		{
+			name:   "added metrics",
+			target: "/db",
+			opts:   []httpbara.ParamsCb{httpbara.WithServerTiming()},
+			header: `^` + total + `, db;dur=1\.500, cache;dur=0\.250$`,
+		},
+		{name: "no body", target: "/empty", opts: []httpbara.ParamsCb{httpbara.WithServerTiming()}, header: `^` + total + `$`},
+		{name: "casual handler", target: "/casual", opts: []httpbara.ParamsCb{httpbara.WithServerTiming()}, header: `^` + total + `$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &serverTimingHandler{})}, tt.opts...)
+
+			rec := serve(h, http.MethodGet, tt.target, nil, nil)
+			got := rec.Header().Get("Server-Timing")
+			if tt.header == "" {
+				if got != "" {
+					t.Fatalf("Server-Timing = %q, want none", got)
+				}
+
+				return
+			}
+
+			if !regexp.MustCompile(tt.header).MatchString(got) {
+				t.Fatalf("Server-Timing = %q, want it to match %s", got, tt.header)
+			}
+		})
+	}
+}
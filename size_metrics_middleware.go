@@ -0,0 +1,88 @@
+package httpbara
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+)
+
+var (
+	ErrSizeRecorderNotSet = errors.New("size recorder is not set")
+)
+
+// SizeRecorder receives the request and response body sizes of every handled request.
+// Implementations usually record them as histograms labeled by route, see the metrics integration packages.
+//
+// Parameters of RecordSizes:
+// - `ctx`: The Gin context of the finished request.
+// - `route`: The matched route pattern (e.g. "/users/:id"), empty when no route matched.
+// - `requestSize`: The request body size in bytes.
+// - `responseSize`: The response body size in bytes.
+type SizeRecorder interface {
+	RecordSizes(ctx *gin.Context, route string, requestSize int64, responseSize int64)
+}
+
+type sizeMetricsMiddlewareDescriber struct {
+	Middleware Middleware `middleware:"sizeMetrics"`
+}
+
+type sizeMetricsMiddleware struct {
+	sizeMetricsMiddlewareDescriber
+
+	recorder SizeRecorder
+}
+
+// NewSizeMetricsMiddleware creates a middleware named "sizeMetrics" that measures request and response body
+// sizes and passes them to the given recorder once the request is handled. Register it with WithRootMiddlewares
+// to measure every route.
+//
+// The request size is taken from the Content-Length header or, for chunked requests, counted while the body is read.
+// The response size is taken from the byte counter of gin.ResponseWriter, so the writer is not replaced and
+// streaming responses relying on http.Flusher or http.Hijacker keep working.
+func NewSizeMetricsMiddleware(recorder SizeRecorder) (*Handler, error) {
+	if recorder == nil {
+		return nil, ErrSizeRecorderNotSet
+	}
+
+	smm := sizeMetricsMiddleware{
+		recorder: recorder,
+	}
+
+	return AsHandler(&smm)
+}
+
+func (smm *sizeMetricsMiddleware) Middleware(ctx *gin.Context) {
+	var body *countingReadCloser
+	if ctx.Request.Body != nil {
+		body = &countingReadCloser{ReadCloser: ctx.Request.Body}
+		ctx.Request.Body = body
+	}
+
+	ctx.Next()
+
+	requestSize := ctx.Request.ContentLength
+	if body != nil && body.n > requestSize {
+		requestSize = body.n
+	}
+
+	responseSize := int64(ctx.Writer.Size())
+	if responseSize < 0 {
+		responseSize = 0
+	}
+
+	smm.recorder.RecordSizes(ctx, ctx.FullPath(), max(requestSize, 0), responseSize)
+}
+
+// countingReadCloser counts the bytes read from the wrapped request body.
+type countingReadCloser struct {
+	io.ReadCloser
+
+	n int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
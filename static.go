@@ -0,0 +1,94 @@
+package httpbara
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io/fs"
+	"net/http"
+	"path"
+	"reflect"
+)
+
+const (
+	// StaticMethod is the method of the route tag declaring a static file route, e.g. `route:"STATIC /assets"`.
+	StaticMethod = "STATIC"
+
+	staticFilepathParam = "filepath"
+)
+
+var (
+	// ErrInvalidStaticRoute is returned by AsHandler when a `STATIC` route has no static handler method,
+	// a static handler method is declared with another method, or it returns no file system.
+	ErrInvalidStaticRoute = errors.New("static route needs a static handler declared with the STATIC method")
+
+	fsType = reflect.TypeOf((*fs.FS)(nil)).Elem()
+)
+
+// isStaticHandler reports whether t is a static handler method: `func() fs.FS` (or any type implementing fs.FS,
+// such as embed.FS) or `func() string` returning a directory path.
+func isStaticHandler(t reflect.Type) bool {
+	if t.NumIn() != 1 || t.NumOut() != 1 {
+		return false
+	}
+
+	return t.Out(0).Kind() == reflect.String || t.Out(0).Implements(fsType)
+}
+
+// staticHandler calls a static handler method once and returns a Gin handler serving the files it returned.
+//
+// Files are served with http.FileServer from the `*filepath` parameter the route path is extended with, so
+// requests are cleaned against the root and cannot traverse out of it. Directories are served through their
+// index.html and are never listed, so the route can serve a single page application build output.
+func staticHandler(name string, method reflect.Value) (gin.HandlerFunc, error) {
+	var fileSystem http.FileSystem
+
+	switch root := method.Call(nil)[0].Interface().(type) {
+	case string:
+		if root == "" {
+			return nil, fmt.Errorf("%w: %s returned an empty directory", ErrInvalidStaticRoute, name)
+		}
+
+		fileSystem = http.Dir(root)
+	case fs.FS:
+		fileSystem = http.FS(root)
+	default:
+		return nil, fmt.Errorf("%w: %s returned no file system", ErrInvalidStaticRoute, name)
+	}
+
+	fileServer := http.FileServer(noListingFileSystem{fileSystem})
+
+	return func(ctx *gin.Context) {
+		req := ctx.Request.Clone(ctx.Request.Context())
+		req.URL.Path = ctx.Param(staticFilepathParam)
+		req.URL.RawPath = ""
+
+		fileServer.ServeHTTP(ctx.Writer, req)
+	}, nil
+}
+
+// noListingFileSystem hides directories without an index.html, so http.FileServer answers 404 instead of a listing.
+type noListingFileSystem struct {
+	http.FileSystem
+}
+
+func (nfs noListingFileSystem) Open(name string) (http.File, error) {
+	file, err := nfs.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil || !info.IsDir() {
+		return file, err
+	}
+
+	index, err := nfs.FileSystem.Open(path.Join(name, "index.html"))
+	if err != nil {
+		_ = file.Close()
+		return nil, fs.ErrNotExist
+	}
+	_ = index.Close()
+
+	return file, nil
+}
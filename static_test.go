@@ -0,0 +1,94 @@
+package httpbara_test
+
+import (
+	"errors"
+	"github.com/gopybara/httpbara"
+	"io/fs"
+	"net/http"
+	"testing"
+	"testing/fstest"
+)
+
+var siteFiles = fstest.MapFS{
+	"index.html": {Data: []byte("<h1>site</h1>")},
+	"app.js":     {Data: []byte("console.log('site')")},
+}
+
+type staticHandlerDescriber struct {
+	Sites httpbara.Route `route:"STATIC /sites/:site" constraints:"site=int,filepath=regex(^/[a-z]*(\\.html|\\.js)?$)"`
+}
+
+type staticHandler struct {
+	staticHandlerDescriber
+}
+
+func (h *staticHandler) Sites() fs.FS {
+	return siteFiles
+}
+
+func TestStaticConstraints(t *testing.T) {
+	h := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &staticHandler{})})
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "valid params", path: "/sites/1/app.js", status: http.StatusOK},
+		{name: "index", path: "/sites/1/", status: http.StatusOK},
+		{name: "invalid site", path: "/sites/acme/app.js", status: http.StatusBadRequest},
+		{name: "invalid file path", path: "/sites/1/app.css", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, tt.path, nil, nil)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
+
+type undeclaredStaticParamHandlerDescriber struct {
+	Sites httpbara.Route `route:"STATIC /sites/:site" constraints:"tenant=int"`
+}
+
+type undeclaredStaticParamHandler struct {
+	undeclaredStaticParamHandlerDescriber
+}
+
+func (h *undeclaredStaticParamHandler) Sites() fs.FS {
+	return siteFiles
+}
+
+type staticConsumesHandlerDescriber struct {
+	Sites httpbara.Route `route:"STATIC /sites" consumes:"application/json"`
+}
+
+type staticConsumesHandler struct {
+	staticConsumesHandlerDescriber
+}
+
+func (h *staticConsumesHandler) Sites() fs.FS {
+	return siteFiles
+}
+
+func TestStaticRouteTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler any
+		err     error
+	}{
+		{name: "constraint on undeclared param", handler: &undeclaredStaticParamHandler{}, err: httpbara.ErrUnknownConstraintParam},
+		{name: "consumes tag", handler: &staticConsumesHandler{}, err: httpbara.ErrUnsupportedConsumesTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := httpbara.AsHandler(tt.handler); !errors.Is(err, tt.err) {
+				t.Fatalf("AsHandler err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"sync"
 	"sync/atomic"
 )
 
@@ -25,6 +26,13 @@ type TaskTracker interface {
 	Shutdown(ctx context.Context) error
 }
 
+// DrainNotifier is implemented by task trackers that can notify when their count of active tasks drops to zero,
+// such as the one created by NewActiveTaskTracker. It is kept apart from TaskTracker so that custom trackers
+// do not have to implement it.
+type DrainNotifier interface {
+	OnDrain(hook func())
+}
+
 // activeTaskTracker is a utility for managing and monitoring a group of
 // concurrent tasks. It keeps track of the number of tasks currently running,
 // and provides a controlled way to initiate shutdown and wait for all tasks
@@ -50,9 +58,19 @@ type activeTaskTracker struct {
 	// Calling it initiates the shutdown process.
 	cancelFunc context.CancelFunc
 
-	// doneCh is a channel used to signal the completion of all remaining
-	// tasks once a shutdown has been initiated.
+	// doneCh is closed to signal the completion of all remaining tasks once
+	// a shutdown has been initiated. Closing instead of sending keeps
+	// FinishTask from blocking when Shutdown has already given up waiting.
 	doneCh chan bool
+
+	// doneOnce guards doneCh from being closed twice.
+	doneOnce sync.Once
+
+	// drainHooks are called whenever FinishTask brings the count to zero.
+	drainHooks []func()
+
+	// drainMu guards drainHooks.
+	drainMu sync.RWMutex
 }
 
 // StartTask increments the count of active tasks by one, representing the start
@@ -75,12 +93,49 @@ func (t *activeTaskTracker) StartTask() error {
 // FinishTask decrements the count of active tasks by one, signaling that a
 // previously started task has completed. If the termination process was
 // initiated (ctx is canceled) and this results in zero remaining tasks, it
-// sends a signal on the doneCh channel to unblock any waiting Shutdown()
-// calls.
+// closes the doneCh channel to unblock any waiting Shutdown() calls. It never
+// blocks, even if Shutdown has already timed out. Every transition of the
+// count to zero also runs the hooks registered via OnDrain.
 func (t *activeTaskTracker) FinishTask() {
-	t.count.Add(-1)
-	if t.ctx.Err() != nil && t.count.Load() == 0 {
-		t.doneCh <- true
+	if t.count.Add(-1) != 0 {
+		return
+	}
+
+	t.runDrainHooks()
+
+	if t.ctx.Err() != nil {
+		t.doneOnce.Do(func() {
+			close(t.doneCh)
+		})
+	}
+}
+
+// OnDrain registers a hook that is called whenever FinishTask brings the
+// count of active tasks to zero, both during normal operation and during
+// shutdown. Hooks run synchronously in the goroutine that finished the last
+// task, in registration order, and fire exactly once per transition to zero.
+// A task started concurrently may already be running when the hook is called.
+//
+// Example usage:
+//
+//	tracker := NewActiveTaskTracker()
+//	tracker.(DrainNotifier).OnDrain(func() {
+//		// All in-flight work is done, checkpoint the batch.
+//	})
+func (t *activeTaskTracker) OnDrain(hook func()) {
+	t.drainMu.Lock()
+	defer t.drainMu.Unlock()
+
+	t.drainHooks = append(t.drainHooks, hook)
+}
+
+func (t *activeTaskTracker) runDrainHooks() {
+	t.drainMu.RLock()
+	hooks := t.drainHooks
+	t.drainMu.RUnlock()
+
+	for _, hook := range hooks {
+		hook()
 	}
 }
 
@@ -98,6 +153,9 @@ func (t *activeTaskTracker) TaskCount() int32 {
 //  2. If there are any active tasks, this method will block until all tasks
 //     have completed and FinishTask sends a completion signal.
 //  3. If no tasks are currently active, Shutdown returns immediately.
+//  4. If ctx is done before all tasks have completed, Shutdown returns an
+//     error reporting how many tasks were still in flight. Those tasks keep
+//     running and can still call FinishTask without blocking.
 //
 // Usage scenario:
 // You might call Shutdown() in response to receiving a termination signal
@@ -112,7 +170,7 @@ func (t *activeTaskTracker) Shutdown(ctx context.Context) error {
 
 	select {
 	case <-ctx.Done():
-		return fmt.Errorf("shutdown timed out: %w", ctx.Err())
+		return fmt.Errorf("shutdown timed out with %d tasks in flight: %w", t.count.Load(), ctx.Err())
 	case <-t.doneCh:
 		return nil
 	}
@@ -144,13 +202,13 @@ func NewActiveTaskTracker() TaskTracker {
 	return att
 }
 
-// GetTaskTracker retrieves the activeTaskTracker instance from the gin.Context.
+// GetTaskTracker retrieves the TaskTracker counting the current request from the gin.Context.
 // Returns an error if task tracker is not provided
 func GetTaskTracker(ctx *gin.Context) (TaskTracker, error) {
-	tracker, exists := ctx.Get("taskTracker")
+	tracker, exists := ctx.Get(taskTrackerKey)
 	if !exists {
 		return nil, ErrTaskTrackerNotFound
 	}
 
-	return tracker.(*activeTaskTracker), nil
+	return tracker.(TaskTracker), nil
 }
@@ -13,6 +13,9 @@ var (
 	ErrShutdown = casual.NewHTTPErrorFromMessage(503, "server is shutting down")
 )
 
+// taskTrackerKey is the gin.Context key under which the request's TaskTracker is stored.
+const taskTrackerKey = "taskTracker"
+
 type taskTrackerMiddlewareDescriber struct {
 	Middleware Middleware `middleware:"taskTracker"`
 }
@@ -24,6 +27,11 @@ type taskTrackerMiddleware struct {
 	tt  TaskTracker
 }
 
+// NewTaskTrackerMiddleware creates a middleware named "taskTracker" that counts requests with tt and answers
+// requests arriving after tt started shutting down with 503.
+//
+// The engine already counts every request with the tracker set via WithTaskTracker (or a default one),
+// so this middleware is only needed to count requests with an additional tracker.
 func NewTaskTrackerMiddleware(log Logger, tt TaskTracker) (*Handler, error) {
 	if tt == nil {
 		return nil, ErrTaskTrackerNotSet
@@ -42,14 +50,41 @@ func NewTaskTrackerMiddleware(log Logger, tt TaskTracker) (*Handler, error) {
 }
 
 func (ttmw *taskTrackerMiddleware) Middleware(ctx *gin.Context) {
-	err := ttmw.tt.StartTask()
+	ttmw.track(ctx, ttmw.tt)
+}
+
+// track counts the request with tt for as long as the remaining handlers run.
+func (ttmw *taskTrackerMiddleware) track(ctx *gin.Context, tt TaskTracker) {
+	err := tt.StartTask()
 	if err != nil {
 		ttmw.log.Error("cannot handle request: server is shutting down", "error", err)
-		ctx.JSON(casual.NewHttpErrorResponse(ErrShutdown))
+		casual.Fail(ctx, ErrShutdown)
 		return
 	}
 
-	defer ttmw.tt.FinishTask()
+	defer tt.FinishTask()
+
+	ctx.Set(taskTrackerKey, tt)
 
 	ctx.Next()
 }
+
+// taskTrackerHandler returns the handler counting every request with the engine's task tracker.
+// The tracker is looked up on every request, as starting the engine again after a shutdown renews it.
+func (c *core) taskTrackerHandler() gin.HandlerFunc {
+	ttmw := &taskTrackerMiddleware{
+		log: c.log,
+	}
+
+	return func(ctx *gin.Context) {
+		ttmw.track(ctx, c.currentTaskTracker())
+	}
+}
+
+// currentTaskTracker returns the task tracker counting the engine's requests.
+func (c *core) currentTaskTracker() TaskTracker {
+	c.trackerMu.RLock()
+	defer c.trackerMu.RUnlock()
+
+	return c.taskTracker
+}
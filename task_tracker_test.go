@@ -0,0 +1,117 @@
+package httpbara_test
+
+import (
+	"context"
+	"errors"
+	"github.com/gopybara/httpbara"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActiveTaskTrackerOnDrain(t *testing.T) {
+	tracker := httpbara.NewActiveTaskTracker()
+
+	notifier, ok := tracker.(httpbara.DrainNotifier)
+	if !ok {
+		t.Fatal("active task tracker does not implement DrainNotifier")
+	}
+
+	drains := 0
+	notifier.OnDrain(func() {
+		drains++
+	})
+
+	for range 2 {
+		if err := tracker.StartTask(); err != nil {
+			t.Fatalf("failed to start task: %v", err)
+		}
+	}
+
+	tracker.FinishTask()
+	if drains != 0 {
+		t.Fatalf("drains = %d with a task still running, want 0", drains)
+	}
+
+	tracker.FinishTask()
+	if drains != 1 {
+		t.Fatalf("drains = %d, want 1", drains)
+	}
+}
+
+func TestActiveTaskTrackerShutdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		started int
+		// finished tasks are done before the shutdown, draining ones while it waits
+		finished  int
+		draining  int
+		wantError string
+	}{
+		{name: "no tasks"},
+		{name: "finished tasks", started: 2, finished: 2},
+		{name: "tasks draining in time", started: 2, draining: 2},
+		{name: "timeout with stragglers", started: 3, finished: 1, wantError: "2 tasks in flight"},
+		{name: "timeout with some drained", started: 3, draining: 1, wantError: "2 tasks in flight"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := httpbara.NewActiveTaskTracker()
+			for range tt.started {
+				if err := tracker.StartTask(); err != nil {
+					t.Fatalf("failed to start task: %v", err)
+				}
+			}
+
+			for range tt.finished {
+				tracker.FinishTask()
+			}
+
+			timeout := time.Second
+			if tt.wantError != "" {
+				timeout = 50 * time.Millisecond
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				for range tt.draining {
+					tracker.FinishTask()
+				}
+			}()
+
+			err := tracker.Shutdown(ctx)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Fatalf("shutdown failed: %v", err)
+				}
+			} else {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) || !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("err = %v, want %q wrapping %v", err, tt.wantError, context.DeadlineExceeded)
+				}
+
+				// The stragglers finish after Shutdown gave up, which must not block
+				finished := make(chan struct{})
+				go func() {
+					for tracker.TaskCount() > 0 {
+						tracker.FinishTask()
+					}
+					close(finished)
+				}()
+
+				select {
+				case <-finished:
+				case <-time.After(time.Second):
+					t.Fatal("FinishTask blocked after the shutdown timed out")
+				}
+			}
+
+			if err := tracker.StartTask(); !errors.Is(err, httpbara.ErrTerminating) {
+				t.Fatalf("StartTask after shutdown = %v, want %v", err, httpbara.ErrTerminating)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package httpbara
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrRouteTimeout is rendered through the casual error responder with 504 when a route exceeds its `timeout` tag.
+	ErrRouteTimeout = casual.NewHTTPErrorFromMessage(http.StatusGatewayTimeout, "gateway timeout")
+)
+
+// timeoutMiddleware builds a Gin handler that replaces the request context with one cancelled after timeout.
+// Casual handlers receive that context as their first argument; plain handlers read it from `ctx.Request.Context()`.
+//
+// The timeout is cooperative: handlers must observe the context for the request to end early. When the deadline
+// has passed and nothing was written yet, a 504 is rendered through the casual error responder.
+func (c *core) timeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		timeoutCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+
+		ctx.Request = ctx.Request.WithContext(timeoutCtx)
+
+		ctx.Next()
+
+		if timedOut(ctx) && !ctx.Writer.Written() {
+			rcb := c.getResponseCallback(ctx)
+			rcb(c.casualResponseErrorHandler(ErrRouteTimeout, languageParams(ctx)...))
+		}
+	}
+}
+
+// timeoutError replaces an error returned by a casual handler with ErrRouteTimeout when the request
+// deadline has passed, so handlers aborted by their route timeout respond with 504.
+func timeoutError(ctx *gin.Context, err error) error {
+	if timedOut(ctx) {
+		return ErrRouteTimeout
+	}
+
+	return err
+}
+
+func timedOut(ctx *gin.Context) bool {
+	return errors.Is(ctx.Request.Context().Err(), context.DeadlineExceeded)
+}
@@ -0,0 +1,109 @@
+package httpbara_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+type totalItem struct {
+	ID int `json:"id"`
+}
+
+// pagedItems is a page of a larger result set, reporting the full total via Meta.
+type pagedItems []totalItem
+
+func (p pagedItems) Meta() map[string]any {
+	return map[string]any{"total": 100}
+}
+
+type totalCountHandlerDescriber struct {
+	List   httpbara.Route `route:"GET /list"`
+	Empty  httpbara.Route `route:"GET /empty"`
+	Paged  httpbara.Route `route:"GET /paged"`
+	Array  httpbara.Route `route:"GET /array"`
+	Single httpbara.Route `route:"GET /single"`
+}
+
+type totalCountHandler struct {
+	totalCountHandlerDescriber
+}
+
+func (h *totalCountHandler) List(ctx context.Context) ([]totalItem, error) {
+	return []totalItem{{ID: 1}, {ID: 2}, {ID: 3}}, nil
+}
+
+func (h *totalCountHandler) Empty(ctx context.Context) ([]totalItem, error) {
+	return []totalItem{}, nil
+}
+
+func (h *totalCountHandler) Paged(ctx context.Context) (pagedItems, error) {
+	return pagedItems{{ID: 1}, {ID: 2}}, nil
+}
+
+func (h *totalCountHandler) Array(ctx context.Context) ([2]totalItem, error) {
+	return [2]totalItem{{ID: 1}, {ID: 2}}, nil
+}
+
+func (h *totalCountHandler) Single(ctx context.Context) (*totalItem, error) {
+	return &totalItem{ID: 1}, nil
+}
+
+func TestWithTotalCountHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		total  string
+	}{
+		{name: "slice", target: "/list", total: "3"},
+		{name: "empty slice", target: "/empty", total: "0"},
+		{name: "total from meta", target: "/paged", total: "100"},
+		{name: "array", target: "/array", total: "2"},
+		{name: "single value", target: "/single"},
+	}
+
+	withHeader := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &totalCountHandler{})}, httpbara.WithTotalCountHeader())
+	withoutHeader := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &totalCountHandler{})})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(withHeader, http.MethodGet, tt.target, nil, map[string]string{"Accept": "application/json"})
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+
+			if got := rec.Header().Get("X-Total-Count"); got != tt.total {
+				t.Fatalf("X-Total-Count = %q, want %q", got, tt.total)
+			}
+
+			var resp struct {
+				Meta  map[string]any `json:"meta"`
+				Total *int           `json:"total"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			// The header matches the total rendered in the body, by the meta or the list envelope
+			if tt.total != "" {
+				var bodyTotal string
+				if total, ok := resp.Meta["total"]; ok {
+					bodyTotal = strconv.FormatFloat(total.(float64), 'f', -1, 64)
+				} else if resp.Total != nil {
+					bodyTotal = strconv.Itoa(*resp.Total)
+				}
+
+				if bodyTotal != tt.total {
+					t.Fatalf("body total = %q, want %q: %s", bodyTotal, tt.total, rec.Body.String())
+				}
+			}
+
+			if rec := serve(withoutHeader, http.MethodGet, tt.target, nil, nil); rec.Header().Get("X-Total-Count") != "" {
+				t.Fatalf("X-Total-Count = %q without the option, want none", rec.Header().Get("X-Total-Count"))
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package httpbara_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gopybara/httpbara"
+	"net/http"
+	"testing"
+)
+
+type slashHandlerDescriber struct {
+	API httpbara.Group `group:"/api/"`
+
+	Root      httpbara.Route `route:"GET /"`
+	Products  httpbara.Route `route:"GET /products/"`
+	Orders    httpbara.Route `route:"GET /orders//"`
+	User      httpbara.Route `route:"GET /users/:id/"`
+	APIIndex  httpbara.Route `route:"GET /" group:"api"`
+	APIItems  httpbara.Route `route:"GET /items/" group:"api"`
+	APIHealth httpbara.Route `route:"GET health" group:"api"`
+}
+
+type slashHandler struct {
+	slashHandlerDescriber
+}
+
+func (h *slashHandler) Root(ctx *gin.Context)      { ctx.String(http.StatusOK, "root") }
+func (h *slashHandler) Products(ctx *gin.Context)  { ctx.String(http.StatusOK, "products") }
+func (h *slashHandler) Orders(ctx *gin.Context)    { ctx.String(http.StatusOK, "orders") }
+func (h *slashHandler) User(ctx *gin.Context)      { ctx.String(http.StatusOK, "user "+ctx.Param("id")) }
+func (h *slashHandler) APIIndex(ctx *gin.Context)  { ctx.String(http.StatusOK, "api") }
+func (h *slashHandler) APIItems(ctx *gin.Context)  { ctx.String(http.StatusOK, "items") }
+func (h *slashHandler) APIHealth(ctx *gin.Context) { ctx.String(http.StatusOK, "health") }
+
+func TestWithTrailingSlashTrimming(t *testing.T) {
+	tests := []struct {
+		name     string
+		declared string
+		trimmed  string
+		target   string
+		wantBody string
+	}{
+		{name: "root", declared: "/", trimmed: "/", target: "/", wantBody: "root"},
+		{name: "single slash", declared: "/products/", trimmed: "/products", target: "/products", wantBody: "products"},
+		{name: "repeated slashes", declared: "/orders//", trimmed: "/orders", target: "/orders", wantBody: "orders"},
+		{name: "path parameter", declared: "/users/:id/", trimmed: "/users/:id", target: "/users/42", wantBody: "user 42"},
+		{name: "group index", declared: "/api/", trimmed: "/api", target: "/api", wantBody: "api"},
+		{name: "group route", declared: "/api/items/", trimmed: "/api/items", target: "/api/items", wantBody: "items"},
+		{name: "group route without slashes", declared: "/api/health", trimmed: "/api/health", target: "/api/health", wantBody: "health"},
+	}
+
+	newEngine := func(t *testing.T, opts ...httpbara.ParamsCb) httpbara.Engine {
+		t.Helper()
+
+		opts = append([]httpbara.ParamsCb{httpbara.WithLogger(discardLogger{}), httpbara.WithTrailingSlashRedirect(false)}, opts...)
+
+		engine, err := httpbara.New([]*httpbara.Handler{mustHandler(t, &slashHandler{})}, opts...)
+		if err != nil {
+			t.Fatalf("failed to create engine: %v", err)
+		}
+
+		return engine
+	}
+
+	paths := func(engine httpbara.Engine) map[string]struct{} {
+		result := make(map[string]struct{})
+		for _, route := range engine.Routes() {
+			result[route.Path] = struct{}{}
+		}
+
+		return result
+	}
+
+	untrimmed := newEngine(t)
+	trimmed := newEngine(t, httpbara.WithTrailingSlashTrimming())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := paths(untrimmed)[tt.declared]; !ok {
+				t.Fatalf("routes without trimming = %v, want %q", paths(untrimmed), tt.declared)
+			}
+
+			if _, ok := paths(trimmed)[tt.trimmed]; !ok {
+				t.Fatalf("routes with trimming = %v, want %q", paths(trimmed), tt.trimmed)
+			}
+
+			rec := serve(trimmed.AsHTTPHandler(), http.MethodGet, tt.target, nil, nil)
+			if rec.Code != http.StatusOK || rec.Body.String() != tt.wantBody {
+				t.Fatalf("GET %s = %d %q, want %d %q", tt.target, rec.Code, rec.Body.String(), http.StatusOK, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package httpbara
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+)
+
+// structValidator adapts a validator instance to gin's binding.StructValidator. Like gin's default validator
+// it validates structs, pointers to structs, and slices and arrays of them.
+type structValidator struct {
+	validate *validator.Validate
+}
+
+func (v *structValidator) ValidateStruct(obj any) error {
+	if obj == nil {
+		return nil
+	}
+
+	value := reflect.ValueOf(obj)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.Elem().Kind() != reflect.Struct {
+			return v.ValidateStruct(value.Elem().Interface())
+		}
+
+		return v.validate.Struct(obj)
+	case reflect.Struct:
+		return v.validate.Struct(obj)
+	case reflect.Slice, reflect.Array:
+		errs := make(binding.SliceValidationError, 0)
+		for i := 0; i < value.Len(); i++ {
+			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if len(errs) == 0 {
+			return nil
+		}
+
+		return errs
+	default:
+		return nil
+	}
+}
+
+func (v *structValidator) Engine() any {
+	return v.validate
+}
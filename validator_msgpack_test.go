@@ -0,0 +1,70 @@
+//go:build !nomsgpack
+
+package httpbara_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gopybara/httpbara"
+	"github.com/gopybara/httpbara/casual"
+	"github.com/ugorji/go/codec"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithValidatorMsgPack(t *testing.T) {
+	engine := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &validatorHandler{})}, httpbara.WithValidator(newNotBlankValidator(t)))
+
+	msgpack := func(name string) string {
+		var buf bytes.Buffer
+		if err := codec.NewEncoder(&buf, new(codec.MsgpackHandle)).Encode(map[string]string{"name": name}); err != nil {
+			t.Fatalf("failed to encode body: %v", err)
+		}
+
+		return buf.String()
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{name: "valid MessagePack", body: msgpack("gopher"), status: http.StatusOK},
+		{name: "invalid MessagePack", body: msgpack("  "), status: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(engine, http.MethodPost, "/notblank", strings.NewReader(tt.body), map[string]string{
+				"Content-Type": "application/msgpack",
+				"Accept":       "application/json",
+			})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.status == http.StatusOK {
+				var resp casual.HttpResponse[notBlankRequest]
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+				}
+
+				if resp.Data.Name != "gopher" {
+					t.Errorf("name = %q, want %q", resp.Data.Name, "gopher")
+				}
+
+				return
+			}
+
+			var resp casual.HttpErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if resp.Error == nil || len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "Name" {
+				t.Fatalf("error = %+v, want a detail for Name", resp.Error)
+			}
+		})
+	}
+}
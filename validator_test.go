@@ -0,0 +1,222 @@
+package httpbara_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/gopybara/httpbara"
+	"github.com/gopybara/httpbara/casual"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type notBlankRequest struct {
+	Name string `json:"name" binding:"notblank"`
+}
+
+type requiredRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type validatorHandlerDescriber struct {
+	NotBlank httpbara.Route `route:"POST /notblank"`
+	Required httpbara.Route `route:"POST /required"`
+}
+
+type validatorHandler struct {
+	validatorHandlerDescriber
+}
+
+func (h *validatorHandler) NotBlank(ctx context.Context, req *notBlankRequest) (*notBlankRequest, error) {
+	return req, nil
+}
+
+func (h *validatorHandler) Required(ctx context.Context, req *requiredRequest) (*requiredRequest, error) {
+	return req, nil
+}
+
+// newNotBlankValidator creates a validator reading the `binding` tag with the custom notblank validation.
+func newNotBlankValidator(t *testing.T) *validator.Validate {
+	t.Helper()
+
+	v := validator.New()
+	v.SetTagName("binding")
+	if err := v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	}); err != nil {
+		t.Fatalf("failed to register validation: %v", err)
+	}
+
+	return v
+}
+
+func TestWithValidator(t *testing.T) {
+	v := newNotBlankValidator(t)
+
+	ginValidator := binding.Validator
+
+	custom := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &validatorHandler{})}, httpbara.WithValidator(v))
+	plain := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &validatorHandler{})})
+
+	if binding.Validator != ginValidator {
+		t.Fatal("WithValidator replaced gin's binding validator")
+	}
+
+	tests := []struct {
+		name   string
+		engine http.Handler
+		path   string
+		body   string
+		status int
+		field  string
+	}{
+		{name: "custom validation passes", engine: custom, path: "/notblank", body: `{"name":"gopher"}`, status: http.StatusOK},
+		{name: "custom validation fails", engine: custom, path: "/notblank", body: `{"name":"   "}`, status: http.StatusUnprocessableEntity, field: "Name"},
+		{name: "built-in tags still apply", engine: custom, path: "/required", body: `{}`, status: http.StatusUnprocessableEntity, field: "Name"},
+		{name: "engine without validator", engine: plain, path: "/required", body: `{}`, status: http.StatusUnprocessableEntity, field: "Name"},
+		{name: "engine without validator passes", engine: plain, path: "/required", body: `{"name":"gopher"}`, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.engine, http.MethodPost, tt.path, strings.NewReader(tt.body), map[string]string{
+				"Content-Type": "application/json",
+			})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.field == "" {
+				return
+			}
+
+			var resp casual.HttpErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if resp.Error == nil || len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != tt.field {
+				t.Fatalf("error = %+v, want a detail for %s", resp.Error, tt.field)
+			}
+		})
+	}
+}
+
+func TestWithValidatorTranslators(t *testing.T) {
+	v := newNotBlankValidator(t)
+
+	uni := ut.New(en.New(), en.New(), de.New())
+	translations := map[string]string{
+		"en": "{0} must not be blank",
+		"de": "{0} darf nicht leer sein",
+	}
+
+	var translators []ut.Translator
+	for _, locale := range []string{"en", "de"} {
+		trans, _ := uni.GetTranslator(locale)
+		if err := v.RegisterTranslation("notblank", trans, func(trans ut.Translator) error {
+			return trans.Add("notblank", translations[locale], true)
+		}, func(trans ut.Translator, fe validator.FieldError) string {
+			msg, _ := trans.T("notblank", fe.Field())
+			return msg
+		}); err != nil {
+			t.Fatalf("failed to register %s translation: %v", locale, err)
+		}
+
+		translators = append(translators, trans)
+	}
+
+	engine := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &validatorHandler{})}, httpbara.WithValidator(v, translators...))
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		language string
+		issue    string
+	}{
+		{name: "matching translator", path: "/notblank", body: `{"name":" "}`, language: "de", issue: "Name darf nicht leer sein"},
+		{name: "default translator", path: "/notblank", body: `{"name":" "}`, issue: "Name must not be blank"},
+		{name: "fallback translator", path: "/notblank", body: `{"name":" "}`, language: "fr", issue: "Name must not be blank"},
+		{name: "tag without translation", path: "/required", body: `{}`, language: "ru", issue: "Поле обязательно для заполнения"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{"Content-Type": "application/json"}
+			if tt.language != "" {
+				headers["Accept-Language"] = tt.language
+			}
+
+			rec := serve(engine, http.MethodPost, tt.path, strings.NewReader(tt.body), headers)
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+			}
+
+			var resp casual.HttpErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if resp.Error == nil || len(resp.Error.Details) != 1 {
+				t.Fatalf("error = %+v, want a single detail", resp.Error)
+			}
+
+			if issue := resp.Error.Details[0].Issue; issue != tt.issue {
+				t.Errorf("issue = %q, want %q", issue, tt.issue)
+			}
+		})
+	}
+}
+
+func TestWithValidatorTOML(t *testing.T) {
+	engine := newTestEngine(t, []*httpbara.Handler{mustHandler(t, &validatorHandler{})}, httpbara.WithValidator(newNotBlankValidator(t)))
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{name: "valid TOML", body: `name = "gopher"`, status: http.StatusOK},
+		{name: "invalid TOML", body: `name = "  "`, status: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(engine, http.MethodPost, "/notblank", strings.NewReader(tt.body), map[string]string{
+				"Content-Type": "application/toml",
+				"Accept":       "application/json",
+			})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.status == http.StatusOK {
+				var resp casual.HttpResponse[notBlankRequest]
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+				}
+
+				if resp.Data.Name != "gopher" {
+					t.Errorf("name = %q, want %q", resp.Data.Name, "gopher")
+				}
+
+				return
+			}
+
+			var resp casual.HttpErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if resp.Error == nil || len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "Name" {
+				t.Fatalf("error = %+v, want a detail for Name", resp.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package httpbara
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"reflect"
+)
+
+// WebsocketMethod is the method of the route tag declaring a websocket route, e.g. `route:"WS /live"`.
+const WebsocketMethod = "WS"
+
+var (
+	// ErrInvalidWebsocketRoute is returned by AsHandler when a `WS` route has no websocket handler method,
+	// or a websocket handler method is declared with another method.
+	ErrInvalidWebsocketRoute = errors.New("websocket route needs a websocket handler declared with the WS method")
+
+	websocketConnType = reflect.TypeOf((*websocket.Conn)(nil))
+)
+
+// websocketHandler serves an upgraded websocket connection.
+type websocketHandler func(ctx *gin.Context, conn *websocket.Conn)
+
+// isWebsocketHandler reports whether t is a websocket handler method: `func(*websocket.Conn)`
+// or `func(*gin.Context, *websocket.Conn)`.
+func isWebsocketHandler(t reflect.Type) bool {
+	if t.NumOut() != 0 {
+		return false
+	}
+
+	switch t.NumIn() {
+	case 2:
+		return t.In(1) == websocketConnType
+	case 3:
+		return t.In(1) == reflect.TypeOf((*gin.Context)(nil)) && t.In(2) == websocketConnType
+	}
+
+	return false
+}
+
+// asWebsocketHandler adapts a bound websocket handler method to websocketHandler.
+func asWebsocketHandler(method any) websocketHandler {
+	switch handler := method.(type) {
+	case func(*websocket.Conn):
+		return func(_ *gin.Context, conn *websocket.Conn) {
+			handler(conn)
+		}
+	case func(*gin.Context, *websocket.Conn):
+		return handler
+	}
+
+	return nil
+}
+
+// WithWebsocketUpgrader sets the upgrader used by `WS` routes, e.g. to configure buffer sizes or the origin check.
+// Without this option a zero websocket.Upgrader is used, which only accepts same-origin requests.
+//
+// **Example:**
+// ```go
+//
+//	engine, _ := httpbara.New(handlers, httpbara.WithWebsocketUpgrader(&websocket.Upgrader{
+//	    ReadBufferSize:  1024,
+//	    WriteBufferSize: 1024,
+//	    CheckOrigin: func(r *http.Request) bool {
+//	        return r.Header.Get("Origin") == "https://shop.example.com"
+//	    },
+//	}))
+//
+// ```
+func WithWebsocketUpgrader(upgrader *websocket.Upgrader) ParamsCb {
+	return func(params *params) error {
+		params.websocketUpgrader = upgrader
+
+		return nil
+	}
+}
+
+// websocketRoute binds a `WS` route to the upgrader of the engine. The returned route upgrades the connection,
+// runs the websocket handler and closes the connection once the handler returns. Failed upgrades are answered
+// by the upgrader itself. The route declared by the Handler is left untouched, so it can be registered on several engines.
+func (c *core) websocketRoute(route *Route) *Route {
+	upgrader := c.websocketUpgrader
+	if upgrader == nil {
+		upgrader = &websocket.Upgrader{}
+	}
+
+	bound := *route
+	bound.handler = func(ctx *gin.Context) {
+		conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+		if err != nil {
+			ctx.Abort()
+			return
+		}
+		defer conn.Close()
+
+		route.websocket(ctx, conn)
+	}
+
+	return &bound
+}